@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"h3-vs-h2-k6/cmd/client/core"
+	"h3-vs-h2-k6/internal/echo"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
+)
+
+// =====================================
+// LARGE OBJECT DOWNLOAD / UPLOAD THROUGHPUT
+// =====================================
+// Skenario bulk transfer untuk melihat efek congestion control,
+// flow-control window, dan goodput antara HTTP/2 dan HTTP/3.
+// Body di-stream lewat raw HTTP handler (/bulk) di echo server,
+// bukan lewat Connect, supaya ukuran MB-GB tidak di-buffer di memori.
+//
+// Metrics per transfer:
+// - goodput (Mbit/s) = payload bytes / total transfer time
+// - TTFB: download = waktu sampai byte body pertama diterima,
+//   upload = waktu sampai transport mulai mengambil byte body pertama
+// - read gaps: jeda antar read body > read gap threshold setelah byte pertama
+//   (download: read oleh client, upload: read body oleh transport). Jeda bisa
+//   karena flow-control window habis, loss recovery atau scheduling; ini
+//   bukan ukuran langsung waktu blocking flow control
+//
+// Window tuning (client): --quic-max-stream-window, --quic-max-conn-window,
+// --h2-stream-window, --h2-conn-window. Server memakai flag yang sama
+// untuk sisi upload.
+//
+// FIXED CONFIGURATION:
+// Config: 1 connection, sequential transfers, sizes 1MB/16MB/128MB/1GB,
+// 3 repeats per size, read gap threshold 50ms
+// =====================================
+
+const (
+	fixedRepeats          = 3
+	fixedReadGapThreshold = 50 * time.Millisecond
+	fixedReadBuffer       = 64 * 1024
+)
+
+var fixedSizes = []int64{
+	1 << 20,   // 1MB
+	16 << 20,  // 16MB
+	128 << 20, // 128MB
+	1 << 30,   // 1GB
+}
+
+// transferResult holds metrics for a single bulk transfer
+type transferResult struct {
+	direction   string
+	size        int64
+	bytes       int64
+	start       time.Time
+	ttfb        time.Duration
+	total       time.Duration
+	readGaps    int
+	readGapTime time.Duration
+	err         error
+}
+
+// goodputMbps returns payload goodput in Mbit/s
+func (r transferResult) goodputMbps() float64 {
+	if r.total <= 0 {
+		return 0
+	}
+	return float64(r.bytes) * 8 / 1e6 / r.total.Seconds()
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	// -------- Flags --------
+	var (
+		addr     = flag.String("addr", "https://localhost:8443", "server URL")
+		useH3    = flag.Bool("h3", true, "use HTTP/3 (true) or HTTP/2 (false)")
+		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
+		mode     = flag.String("mode", "download", "download|upload|both (transfer direction)")
+		maxSize  = flag.Int64("max-size", 1<<30, "skip fixed transfer sizes above this many bytes")
+
+		// Output only
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Bulk Transfer Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
+	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
+	flag.Parse()
+
+	// Validate mode
+	var directions []string
+	switch *mode {
+	case "download", "upload":
+		directions = []string{*mode}
+	case "both":
+		directions = []string{"download", "upload"}
+	default:
+		log.Printf("unknown --mode: %s (valid: download, upload, both)", *mode)
+		return 1
+	}
+
+	var sizes []int64
+	for _, sz := range fixedSizes {
+		if sz <= *maxSize {
+			sizes = append(sizes, sz)
+		}
+	}
+	if len(sizes) == 0 {
+		log.Printf("--max-size %d excludes every fixed transfer size", *maxSize)
+		return 1
+	}
+
+	// ---- Setup Logger ----
+	logLevel := core.LogLevelNormal
+	if *quiet {
+		logLevel = core.LogLevelMinimal
+	}
+	if *verbose {
+		logLevel = core.LogLevelVerbose
+	}
+	logger := core.NewLogger(logLevel)
+
+	// ---- Startup logging ----
+	cwd, _ := os.Getwd()
+	config := map[string]interface{}{
+		"scenario":           "bulk-transfer",
+		"pid":                os.Getpid(),
+		"cwd":                cwd,
+		"addr":               *addr,
+		"protocol":           core.ProtocolName(*useH3),
+		"insecure":           *insecure,
+		"mode":               *mode,
+		"sizes":              formatSizes(sizes),
+		"repeats":            fixedRepeats,
+		"read_gap_threshold": fixedReadGapThreshold,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("bulk-transfer", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-bulk-transfer")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
+
+	// Absolutkan output path
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
+
+	// Build HTTP client (single shared connection, transfers run sequentially)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
+	defer closer()
+	bulkURL := strings.TrimRight(*addr, "/") + echo.BulkPath
+
+	// Context & cancel
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
+
+	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "bulk-transfer", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
+	if !*quiet {
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
+	}
+
+	// Start benchmark
+	start := time.Now()
+	logger.Info("Starting bulk transfer benchmark...")
+
+	var results []transferResult
+	var all []core.Record
+run:
+	for _, dir := range directions {
+		for _, size := range sizes {
+			for rep := 0; rep < fixedRepeats; rep++ {
+				select {
+				case <-ctx.Done():
+					logger.Info("Benchmark cancelled")
+					break run
+				default:
+				}
+
+				// Per-request deadline covers the whole transfer
+				tctx, tcancel := ctx, context.CancelFunc(func() {})
+				if d := core.RequestTimeout(ctx); d > 0 {
+					tctx, tcancel = context.WithTimeout(ctx, d)
+				}
+				var res transferResult
+				if dir == "download" {
+					res = download(tctx, httpClient, bulkURL, size)
+				} else {
+					res = upload(tctx, httpClient, bulkURL, size)
+				}
+				errClass, timedOut := core.ClassifyRequestError(tctx, res.err)
+				tcancel()
+				results = append(results, res)
+
+				ok := res.err == nil
+				counters.Observe(res.total, ok, errClass, timedOut)
+				if !ok {
+					logger.Error(int64(len(results)), res.err)
+				}
+				all = append(all, core.Record{
+					TsUnixNS:  res.start.UnixNano(),
+					LatencyNS: res.total.Nanoseconds(),
+					OK:        ok,
+					ErrClass:  errClass,
+					Timeout:   timedOut,
+				})
+
+				logger.Info("%s %s #%d: %s in %v ttfb=%v goodput=%.2f Mbit/s read_gaps=%d (%v)",
+					dir, core.FormatBytes(int(size)), rep+1, core.FormatBytes(int(res.bytes)),
+					res.total, res.ttfb, res.goodputMbps(), res.readGaps, res.readGapTime)
+			}
+		}
+	}
+	cancel()
+	progress.Wait()
+
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
+
+	// Print results
+	fmt.Printf("\n")
+	stats := map[string]interface{}{
+		"scenario":  "bulk_transfer",
+		"protocol":  core.ProtocolName(*useH3),
+		"mode":      *mode,
+		"transfers": len(results),
+		"ok_rate_%": fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":  sum.Timeouts,
+	}
+	core.MergeFields(stats, tcfg.Fields())
+	for _, dir := range directions {
+		for _, size := range sizes {
+			key := fmt.Sprintf("%s_%s", dir, formatSize(size))
+			var n, readGaps int
+			var goodput float64
+			var ttfb, readGapTime time.Duration
+			for _, r := range results {
+				if r.direction != dir || r.size != size || r.err != nil {
+					continue
+				}
+				n++
+				goodput += r.goodputMbps()
+				ttfb += r.ttfb
+				readGaps += r.readGaps
+				readGapTime += r.readGapTime
+			}
+			if n == 0 {
+				continue
+			}
+			stats[key+"_goodput_mbps"] = fmt.Sprintf("%.2f", goodput/float64(n))
+			stats[key+"_ttfb_ms"] = fmt.Sprintf("%.3f", float64(ttfb.Nanoseconds())/float64(n)/1e6)
+			stats[key+"_read_gaps"] = readGaps
+			stats[key+"_read_gap_ms"] = fmt.Sprintf("%.3f", float64(readGapTime.Nanoseconds())/1e6)
+		}
+	}
+	logger.Summary(stats)
+
+	// Also log in standard format for backward compatibility
+	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
+		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
+
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
+
+	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
+}
+
+// download fetches size bytes from the bulk handler and times every body read
+func download(ctx context.Context, cl *http.Client, url string, size int64) transferResult {
+	res := transferResult{direction: "download", size: size, start: time.Now()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?size=%d", url, size), nil)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	resp, err := cl.Do(req)
+	if err != nil {
+		res.err = err
+		res.total = time.Since(res.start)
+		return res
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		res.err = fmt.Errorf("unexpected status %s", resp.Status)
+		res.total = time.Since(res.start)
+		return res
+	}
+
+	buf := make([]byte, fixedReadBuffer)
+	var last time.Time
+	for {
+		n, rerr := resp.Body.Read(buf)
+		now := time.Now()
+		if n > 0 {
+			if res.bytes == 0 {
+				res.ttfb = now.Sub(res.start)
+			} else if gap := now.Sub(last); gap > fixedReadGapThreshold {
+				res.readGaps++
+				res.readGapTime += gap
+			}
+			res.bytes += int64(n)
+			last = now
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			res.err = rerr
+			break
+		}
+	}
+	res.total = time.Since(res.start)
+	if res.err == nil && res.bytes != size {
+		res.err = fmt.Errorf("short body: got %d of %d bytes", res.bytes, size)
+	}
+	return res
+}
+
+// upload posts size bytes to the bulk handler and times every body pull by the transport
+func upload(ctx context.Context, cl *http.Client, url string, size int64) transferResult {
+	res := transferResult{direction: "upload", size: size, start: time.Now()}
+
+	body := &gapReader{remaining: size, res: &res}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := cl.Do(req)
+	if err != nil {
+		res.err = err
+		res.total = time.Since(res.start)
+		return res
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	res.total = time.Since(res.start)
+
+	if resp.StatusCode != http.StatusOK {
+		res.err = fmt.Errorf("unexpected status %s", resp.Status)
+	} else if got := resp.Header.Get("x-bulk-received"); got != fmt.Sprint(size) {
+		res.err = fmt.Errorf("server received %s of %d bytes", got, size)
+	}
+	return res
+}
+
+// gapReader generates an upload body and records gaps between reads by the transport
+type gapReader struct {
+	remaining int64
+	last      time.Time
+	res       *transferResult
+}
+
+var zeroChunk = bytes.Repeat([]byte{0}, fixedReadBuffer)
+
+func (r *gapReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	now := time.Now()
+	if r.res.bytes == 0 {
+		r.res.ttfb = now.Sub(r.res.start)
+	} else if gap := now.Sub(r.last); gap > fixedReadGapThreshold {
+		r.res.readGaps++
+		r.res.readGapTime += gap
+	}
+	r.last = now
+
+	n := int64(len(p))
+	if n > int64(len(zeroChunk)) {
+		n = int64(len(zeroChunk))
+	}
+	if n > r.remaining {
+		n = r.remaining
+	}
+	copy(p, zeroChunk[:n])
+	r.remaining -= n
+	r.res.bytes += n
+	return int(n), nil
+}
+
+// formatSize renders a byte count as a compact label (1MB, 1GB)
+func formatSize(b int64) string {
+	switch {
+	case b >= 1<<30 && b%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", b>>30)
+	case b >= 1<<20 && b%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", b>>20)
+	case b >= 1<<10 && b%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", b>>10)
+	}
+	return fmt.Sprintf("%dB", b)
+}
+
+// formatSizes joins compact size labels
+func formatSizes(sizes []int64) string {
+	parts := make([]string, len(sizes))
+	for i, sz := range sizes {
+		parts[i] = formatSize(sz)
+	}
+	return strings.Join(parts, ",")
+}
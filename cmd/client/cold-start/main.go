@@ -2,11 +2,9 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -14,10 +12,9 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/quic-go/quic-go/http3"
-	"golang.org/x/net/http2"
 	"h3-vs-h2-k6/cmd/client/core"
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -35,13 +32,17 @@ import (
 // =====================================
 
 const (
-	fixedWorkers          = 1000
+	fixedWorkers           = 1000
 	fixedRequestsPerWorker = 100
-	fixedRequestInterval  = 30 * time.Millisecond
-	fixedPayload          = 512
+	fixedRequestInterval   = 30 * time.Millisecond
+	fixedPayload           = 512
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -50,17 +51,34 @@ func main() {
 		mode     = flag.String("mode", "warm", "cold|warm (connection mode)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "Cold-Start vs Resumed Benchmark", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Cold-Start vs Resumed Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedWorkers, Payload: fixedPayload})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
 
 	// Validate mode
 	if *mode != "cold" && *mode != "warm" {
-		log.Fatalf("unknown --mode: %s (valid: cold, warm)", *mode)
+		log.Printf("unknown --mode: %s (valid: cold, warm)", *mode)
+		return 1
 	}
 
 	// ---- Setup Logger ----
@@ -75,34 +93,65 @@ func main() {
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("cold-start", map[string]interface{}{
+	config := map[string]interface{}{
+		"scenario":            "cold-start",
 		"pid":                 os.Getpid(),
 		"cwd":                 cwd,
 		"addr":                *addr,
 		"protocol":            core.ProtocolName(*useH3),
 		"mode":                *mode,
 		"insecure":            *insecure,
-		"workers":             fixedWorkers,
+		"workers":             load.Workers,
 		"requests_per_worker": fixedRequestsPerWorker,
 		"request_interval":    fixedRequestInterval,
-		"payload":             fixedPayload,
-	})
+		"payload":             load.Payload,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("cold-start", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-cold-start")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
 	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Channels & counters
 	latCh := make(chan core.Record, 1<<20)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "cold-start", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
 	var reqCounter atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
 	// Collector goroutine
@@ -122,19 +171,21 @@ func main() {
 	start := time.Now()
 	logger.Info("Starting cold-start benchmark in %s mode...", *mode)
 
+	// Request function shared by every worker, wrapped in the retry/hedge policy once
+	requestFn := policy.Wrap(core.SimpleRequest(load.Payload))
+
 	// Start workers
 	var wg sync.WaitGroup
-	wg.Add(fixedWorkers)
+	wg.Add(load.Workers)
 
 	if *mode == "warm" {
 		// WARM MODE: reuse persistent connection
 		// Build shared HTTP client
-		httpClient, closer := newHTTPClient(*useH3, *insecure)
+		httpClient, closer := core.BuildHTTPClient(*useH3, *insecure, tcfg)
 		defer closer()
-		client := echov1connect.NewEchoServiceClient(httpClient, *addr)
-		requestFn := core.SimpleRequest(fixedPayload)
+		client := core.NewEchoClient(httpClient, *addr)
 
-		for i := 0; i < fixedWorkers; i++ {
+		for i := 0; i < load.Workers; i++ {
 			go func(workerID int) {
 				defer wg.Done()
 				logger.Debug("Worker %d started (warm mode)", workerID)
@@ -160,7 +211,7 @@ func main() {
 		}
 	} else {
 		// COLD MODE: create new connection for each request
-		for i := 0; i < fixedWorkers; i++ {
+		for i := 0; i < load.Workers; i++ {
 			go func(workerID int) {
 				defer wg.Done()
 				logger.Debug("Worker %d started (cold mode)", workerID)
@@ -174,9 +225,8 @@ func main() {
 					}
 
 					// Create NEW client for each request
-					httpClient, closer := newHTTPClient(*useH3, *insecure)
-					client := echov1connect.NewEchoServiceClient(httpClient, *addr)
-					requestFn := core.SimpleRequest(fixedPayload)
+					httpClient, closer := core.BuildHTTPClient(*useH3, *insecure, tcfg)
+					client := core.NewEchoClient(httpClient, *addr)
 
 					reqID := reqCounter.Add(1)
 					core.DoRequest(ctx, client, latCh, counters, logger, reqID, requestFn)
@@ -197,12 +247,16 @@ func main() {
 	// Wait for all workers to finish
 	wg.Wait()
 	cancel()
+	progress.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
 	mu.Unlock()
 
 	// Print results
@@ -211,11 +265,12 @@ func main() {
 		"scenario":            "cold_start",
 		"mode":                *mode,
 		"protocol":            core.ProtocolName(*useH3),
-		"workers":             fixedWorkers,
+		"workers":             load.Workers,
 		"requests_per_worker": fixedRequestsPerWorker,
-		"total_requests":      fixedWorkers * fixedRequestsPerWorker,
+		"total_requests":      load.Workers * fixedRequestsPerWorker,
 		"samples":             sum.Samples,
 		"ok_rate_%":           fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":            sum.Timeouts,
 		"rps":                 fmt.Sprintf("%.2f", sum.RPS),
 		"p50_ms":              fmt.Sprintf("%.6f", sum.P50ms),
 		"p90_ms":              fmt.Sprintf("%.6f", sum.P90ms),
@@ -229,38 +284,18 @@ func main() {
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
-
-	logger.Info("Total runtime: %v", time.Since(start))
-}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
 
-// newHTTPClient creates an HTTP client (H2 or H3) with TLS config
-func newHTTPClient(useH3 bool, insecure bool) (*http.Client, func()) {
-	tlsCfg := &tls.Config{
-		MinVersion:         tls.VersionTLS13,
-		InsecureSkipVerify: insecure,
-	}
-
-	if useH3 {
-		tr := &http3.Transport{TLSClientConfig: tlsCfg}
-		return &http.Client{Transport: tr, Timeout: 0}, func() { tr.CloseIdleConnections() }
-	}
+	out.Write(*label, all, sum, logger)
 
-	h2 := &http.Transport{
-		TLSClientConfig:   tlsCfg,
-		ForceAttemptHTTP2: true,
-	}
-	_ = http2.ConfigureTransport(h2)
-	return &http.Client{Transport: h2, Timeout: 0}, func() {}
+	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
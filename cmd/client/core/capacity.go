@@ -0,0 +1,237 @@
+package core
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// Capacity configures a capacity search: the offered load is stepped up
+// until the SLO breaks, then bisected down to the highest passing rate
+type Capacity struct {
+	Enabled       bool
+	StartRPS      int           // First offered load, doubled until the SLO breaks
+	MaxRPS        int           // Search ceiling
+	Hold          time.Duration // Time each offered load must hold the SLO
+	Settle        time.Duration // Start of each step excluded from the SLO check
+	SLOP99        time.Duration // p99 latency budget
+	SLOErrorPct   float64       // Failed request budget in percent
+	MinThroughput float64       // Started requests as a percentage of offered load
+	PrecisionPct  float64       // Bisection stops when the bracket is this narrow
+	DrainTimeout  time.Duration // Wait for in-flight requests between steps
+	MaxProbes     int           // Probe budget, safety against slow searches
+}
+
+// RegisterCapacityFlags registers capacity search flags; maxRPS is the
+// scenario's peak load, used as the search ceiling
+func RegisterCapacityFlags(fs *flag.FlagSet, maxRPS int) *Capacity {
+	c := &Capacity{}
+	fs.BoolVar(&c.Enabled, "capacity", false, "search the highest RPS that holds the SLO instead of the fixed ramp")
+	fs.IntVar(&c.StartRPS, "capacity-start", 500, "first offered RPS, doubled until the SLO breaks")
+	fs.IntVar(&c.MaxRPS, "capacity-max", maxRPS, "highest offered RPS")
+	fs.DurationVar(&c.Hold, "capacity-hold", 20*time.Second, "time each offered load must hold the SLO")
+	fs.DurationVar(&c.Settle, "capacity-settle", 2*time.Second, "start of each step excluded from the SLO check")
+	fs.DurationVar(&c.SLOP99, "slo-p99", 100*time.Millisecond, "SLO: p99 latency")
+	fs.Float64Var(&c.SLOErrorPct, "slo-error-rate", 1, "SLO: failed requests in percent")
+	fs.Float64Var(&c.MinThroughput, "slo-min-throughput", 95, "SLO: started requests as a percentage of offered load")
+	fs.Float64Var(&c.PrecisionPct, "capacity-precision", 5, "stop bisecting when the pass/fail bracket is within this percentage")
+	fs.DurationVar(&c.DrainTimeout, "capacity-drain", 10*time.Second, "max wait for in-flight requests between steps")
+	fs.IntVar(&c.MaxProbes, "capacity-max-probes", 20, "max offered load steps")
+	return c
+}
+
+// Fields returns the search values for startup logs and results
+func (c *Capacity) Fields() map[string]interface{} {
+	if !c.Enabled {
+		return map[string]interface{}{"capacity": false}
+	}
+	return map[string]interface{}{
+		"capacity":           true,
+		"capacity_start":     c.StartRPS,
+		"capacity_max":       c.MaxRPS,
+		"capacity_hold":      c.Hold,
+		"capacity_settle":    c.Settle,
+		"capacity_precision": c.PrecisionPct,
+		"slo_p99":            c.SLOP99,
+		"slo_error_rate":     c.SLOErrorPct,
+		"slo_min_throughput": c.MinThroughput,
+	}
+}
+
+// Validate checks the flag combination
+func (c *Capacity) Validate() error {
+	switch {
+	case c.StartRPS <= 0 || c.MaxRPS < c.StartRPS:
+		return fmt.Errorf("capacity: need 0 < --capacity-start <= --capacity-max")
+	case c.Hold <= c.Settle:
+		return fmt.Errorf("capacity: --capacity-hold must be longer than --capacity-settle")
+	case c.PrecisionPct <= 0:
+		return fmt.Errorf("capacity: --capacity-precision must be > 0")
+	}
+	return nil
+}
+
+// MaxDuration bounds the search time, for the scenario safety timeout
+func (c *Capacity) MaxDuration() time.Duration {
+	return time.Duration(c.MaxProbes) * (c.Hold + c.DrainTimeout)
+}
+
+// CapacityProbe is the outcome of one offered load level
+type CapacityProbe struct {
+	TargetRPS   int     // Offered load
+	Samples     int     // Requests started after the settle time
+	AchievedRPS float64 // Started requests per second after the settle time
+	OKRatePct   float64
+	P50ms       float64
+	P90ms       float64
+	P99ms       float64
+	Pass        bool
+	Reason      string // First SLO breached, empty when passing
+}
+
+// CapacityResult is the outcome of a capacity search
+type CapacityResult struct {
+	KneeRPS     int     // Highest offered load that held the SLO (0 = none)
+	KneeP99ms   float64 // p99 at the knee
+	Capped      bool    // Knee is the search ceiling, real capacity may be higher
+	SLOP99ms    float64
+	SLOErrorPct float64
+	HoldS       float64
+	Probes      []CapacityProbe // In search order, see Curve
+}
+
+// Active reports whether a capacity search ran
+func (r CapacityResult) Active() bool {
+	return len(r.Probes) > 0
+}
+
+// Curve returns the probes sorted by offered load
+func (r CapacityResult) Curve() []CapacityProbe {
+	out := append([]CapacityProbe(nil), r.Probes...)
+	sort.Slice(out, func(i, j int) bool { return out[i].TargetRPS < out[j].TargetRPS })
+	return out
+}
+
+// CapacityPhase names the phase of one offered load step
+func CapacityPhase(rps int) string {
+	return fmt.Sprintf("rps=%d", rps)
+}
+
+// Evaluate checks the records of one step against the SLO. Records are
+// those tagged with the step's phase; the settle time is dropped
+func (c *Capacity) Evaluate(rps int, start time.Time, recs []Record) CapacityProbe {
+	from := start.Add(c.Settle).UnixNano()
+	var held []Record
+	for _, r := range recs {
+		if r.TsUnixNS >= from {
+			held = append(held, r)
+		}
+	}
+	p := CapacityProbe{TargetRPS: rps, Samples: len(held)}
+	s := summarize(held)
+	p.OKRatePct, p.P50ms, p.P90ms, p.P99ms = s.OKRatePct, s.P50ms, s.P90ms, s.P99ms
+	p.AchievedRPS = Round6(float64(len(held)) / (c.Hold - c.Settle).Seconds())
+
+	sloP99 := float64(c.SLOP99) / float64(time.Millisecond)
+	switch {
+	case len(held) == 0:
+		p.Reason = "no requests"
+	case 100-p.OKRatePct > c.SLOErrorPct:
+		p.Reason = fmt.Sprintf("error rate %.2f%% > %.2f%%", 100-p.OKRatePct, c.SLOErrorPct)
+	case p.P99ms > sloP99:
+		p.Reason = fmt.Sprintf("p99 %.1fms > %.1fms", p.P99ms, sloP99)
+	case p.AchievedRPS < float64(rps)*c.MinThroughput/100:
+		p.Reason = fmt.Sprintf("throughput %.0f < %.0f%% of %d", p.AchievedRPS, c.MinThroughput, rps)
+	default:
+		p.Pass = true
+	}
+	return p
+}
+
+// Search steps the offered load up from StartRPS, doubling while the SLO
+// holds, then bisects between the last pass and the first failure. probe
+// runs one step at the given rate and returns its evaluation
+func (c *Capacity) Search(ctx context.Context, probe func(rps int) CapacityProbe, logger *Logger) CapacityResult {
+	res := CapacityResult{
+		SLOP99ms:    float64(c.SLOP99) / float64(time.Millisecond),
+		SLOErrorPct: c.SLOErrorPct,
+		HoldS:       c.Hold.Seconds(),
+	}
+	run := func(rps int) bool {
+		p := probe(rps)
+		if ctx.Err() != nil {
+			return false // Interrupted step, not a verdict
+		}
+		res.Probes = append(res.Probes, p)
+		logger.CapacityProbe(len(res.Probes), p)
+		if p.Pass && rps > res.KneeRPS {
+			res.KneeRPS, res.KneeP99ms = rps, p.P99ms
+		}
+		return p.Pass
+	}
+	more := func() bool { return ctx.Err() == nil && len(res.Probes) < c.MaxProbes }
+
+	// Step up
+	lo, hi := 0, 0
+	for rps := c.StartRPS; more(); rps = min(rps*2, c.MaxRPS) {
+		if !run(rps) {
+			hi = rps
+			break
+		}
+		lo = rps
+		if rps == c.MaxRPS {
+			res.Capped = true
+			return res
+		}
+	}
+
+	// Bisect
+	for hi > 0 && more() {
+		if float64(hi-lo) <= float64(hi)*c.PrecisionPct/100 {
+			break
+		}
+		mid := (lo + hi) / 2
+		if mid <= lo || mid < 1 {
+			break
+		}
+		if run(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return res
+}
+
+// CapacityProbe logs one step of a capacity search
+func (l *Logger) CapacityProbe(n int, p CapacityProbe) {
+	if l.level < LogLevelMinimal {
+		return
+	}
+	verdict := "pass"
+	if !p.Pass {
+		verdict = "fail: " + p.Reason
+	}
+	log.Printf("capacity | step=%d target_rps=%d achieved_rps=%.1f samples=%d ok_rate=%.2f%% p50=%.3fms p99=%.3fms %s",
+		n, p.TargetRPS, p.AchievedRPS, p.Samples, p.OKRatePct, p.P50ms, p.P99ms, verdict)
+}
+
+// Capacity logs the knee of a capacity search
+func (l *Logger) Capacity(s Summary) {
+	if l.level < LogLevelMinimal || !s.Capacity.Active() {
+		return
+	}
+	c := s.Capacity
+	switch {
+	case c.KneeRPS == 0:
+		log.Printf("capacity | no offered load held the SLO (p99<=%.1fms, errors<=%.2f%%)", c.SLOP99ms, c.SLOErrorPct)
+	case c.Capped:
+		log.Printf("capacity | knee >= %d RPS (search ceiling) p99=%.3fms", c.KneeRPS, c.KneeP99ms)
+	default:
+		log.Printf("capacity | knee=%d RPS p99=%.3fms (SLO p99<=%.1fms, errors<=%.2f%%, hold %.0fs)",
+			c.KneeRPS, c.KneeP99ms, c.SLOP99ms, c.SLOErrorPct, c.HoldS)
+	}
+}
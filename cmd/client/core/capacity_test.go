@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCapacity() *Capacity {
+	return &Capacity{
+		Enabled:       true,
+		StartRPS:      100,
+		MaxRPS:        10_000,
+		Hold:          10 * time.Second,
+		Settle:        2 * time.Second,
+		SLOP99:        100 * time.Millisecond,
+		SLOErrorPct:   1,
+		MinThroughput: 95,
+		PrecisionPct:  5,
+		MaxProbes:     20,
+	}
+}
+
+func TestCapacitySearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		knee       int // Highest rate the fake server sustains
+		start, max int
+		maxProbes  int
+		wantKnee   int
+		wantCapped bool
+		wantProbes []int // Offered loads in search order
+	}{
+		{
+			name: "bisects between last pass and first fail", knee: 1000, start: 100, max: 10_000, maxProbes: 20,
+			wantKnee: 1000, wantProbes: []int{100, 200, 400, 800, 1600, 1200, 1000, 1100, 1050},
+		},
+		{
+			name: "capped at ceiling", knee: 1_000_000, start: 100, max: 1000, maxProbes: 20,
+			wantKnee: 1000, wantCapped: true, wantProbes: []int{100, 200, 400, 800, 1000},
+		},
+		{
+			name: "first step fails", knee: 50, start: 100, max: 1000, maxProbes: 20,
+			wantKnee: 50, wantProbes: []int{100, 50, 75, 62, 56, 53, 51},
+		},
+		{
+			name: "nothing passes", knee: 0, start: 1, max: 1000, maxProbes: 20,
+			wantKnee: 0, wantProbes: []int{1},
+		},
+		{
+			name: "probe budget", knee: 1000, start: 100, max: 10_000, maxProbes: 6,
+			wantKnee: 800, wantProbes: []int{100, 200, 400, 800, 1600, 1200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCapacity()
+			c.StartRPS, c.MaxRPS, c.MaxProbes = tt.start, tt.max, tt.maxProbes
+			probe := func(rps int) CapacityProbe {
+				return CapacityProbe{TargetRPS: rps, Pass: rps <= tt.knee, P99ms: float64(rps) / 100}
+			}
+			res := c.Search(context.Background(), probe, NewLogger(LogLevelQuiet))
+
+			var got []int
+			for _, p := range res.Probes {
+				got = append(got, p.TargetRPS)
+			}
+			if !slices.Equal(got, tt.wantProbes) {
+				t.Errorf("probes = %v, want %v", got, tt.wantProbes)
+			}
+			if res.KneeRPS != tt.wantKnee {
+				t.Errorf("KneeRPS = %d, want %d", res.KneeRPS, tt.wantKnee)
+			}
+			if res.Capped != tt.wantCapped {
+				t.Errorf("Capped = %v, want %v", res.Capped, tt.wantCapped)
+			}
+		})
+	}
+}
+
+func TestCapacitySearchInterrupted(t *testing.T) {
+	c := testCapacity()
+	ctx, cancel := context.WithCancel(context.Background())
+	probe := func(rps int) CapacityProbe {
+		if rps >= 400 {
+			cancel()
+		}
+		return CapacityProbe{TargetRPS: rps, Pass: true}
+	}
+	res := c.Search(ctx, probe, NewLogger(LogLevelQuiet))
+	if len(res.Probes) != 2 || res.KneeRPS != 200 || res.Capped {
+		t.Errorf("interrupted search = %d probes, knee %d, capped %v; want 2 probes, knee 200", len(res.Probes), res.KneeRPS, res.Capped)
+	}
+}
+
+func TestCapacityEvaluate(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	// recs spreads n requests evenly over the hold time, failing every
+	// failEvery-th one (0 = none)
+	recs := func(n int, lat time.Duration, failEvery int) []Record {
+		c := testCapacity()
+		out := make([]Record, n)
+		for i := range out {
+			out[i] = Record{
+				TsUnixNS:  start.UnixNano() + int64(i)*c.Hold.Nanoseconds()/int64(n),
+				LatencyNS: lat.Nanoseconds(),
+				OK:        failEvery == 0 || i%failEvery != 0,
+			}
+		}
+		return out
+	}
+	tests := []struct {
+		name       string
+		rps        int
+		recs       []Record
+		wantPass   bool
+		wantReason string
+	}{
+		{"holds the SLO", 100, recs(1000, 10*time.Millisecond, 0), true, ""},
+		{"no requests", 100, nil, false, "no requests"},
+		{"settle only", 100, recs(10, 10*time.Millisecond, 0)[:2], false, "no requests"},
+		{"error rate", 100, recs(1000, 10*time.Millisecond, 10), false, "error rate"},
+		{"p99 over budget", 100, recs(1000, 150*time.Millisecond, 0), false, "p99"},
+		{"throughput short", 200, recs(1000, 10*time.Millisecond, 0), false, "throughput"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testCapacity().Evaluate(tt.rps, start, tt.recs)
+			if p.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (reason %q)", p.Pass, tt.wantPass, p.Reason)
+			}
+			if !strings.HasPrefix(p.Reason, tt.wantReason) {
+				t.Errorf("Reason = %q, want prefix %q", p.Reason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Charts are rendered to inline SVG so reports stay a single self-contained
+// file that renders without network access
+
+// ChartPoint is one (x, y) sample of a chart line. NaN y leaves a gap
+type ChartPoint struct {
+	X, Y float64
+}
+
+// ChartLine is one dataset of a Chart
+type ChartLine struct {
+	Label   string
+	Points  []ChartPoint
+	Dashed  bool
+	Markers bool // Dot per point, for sparse data
+	Right   bool // Plotted against the right y axis
+}
+
+// Chart is a line chart rendered as inline SVG
+type Chart struct {
+	XTitle      string
+	YTitle      string
+	Y2Title     string   // Right axis title, for lines with Right set
+	XLog        bool     // Log10 x axis, points with x <= 0 are dropped
+	XTime       bool     // x is Unix seconds, ticks show the wall clock
+	XCategories []string // Category x axis, point x is the category index
+	YMin        *float64 // Axis bounds, widened to fit the data; nil = from data
+	YMax        *float64 // Left axis only
+	Lines       []ChartLine
+}
+
+// Float returns a pointer to v, for Chart.YMin/YMax
+func Float(v float64) *float64 {
+	return &v
+}
+
+var chartSeq atomic.Uint64
+
+// Line colors (Chart.js default palette)
+var chartColors = []string{"#36a2eb", "#ff6384", "#4bc0c0", "#ff9f40", "#9966ff", "#ffcd56", "#c9cbcf", "#2e7d32", "#8d6e63", "#5c6bc0"}
+
+const (
+	chartW       = 720
+	chartH       = 360
+	chartTop     = 44
+	chartBottom  = 48
+	chartLeft    = 64
+	chartRight   = 20
+	chartRight2  = 64 // With a right axis
+	chartMaxPts  = 1500
+	chartTickNum = 6
+)
+
+// SVG renders the chart
+func (c Chart) SVG() template.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg" font-family="sans-serif" font-size="11">`, chartW, chartH)
+
+	hasRight := false
+	for _, l := range c.Lines {
+		hasRight = hasRight || l.Right
+	}
+	left, right := float64(chartLeft), float64(chartW-chartRight)
+	if hasRight {
+		right = float64(chartW - chartRight2)
+	}
+	top, bottom := float64(chartTop), float64(chartH-chartBottom)
+
+	// Data bounds
+	xmin, xmax := math.Inf(1), math.Inf(-1)
+	ymin, ymax := [2]float64{math.Inf(1), math.Inf(1)}, [2]float64{math.Inf(-1), math.Inf(-1)}
+	lines := make([][]ChartPoint, len(c.Lines))
+	for i, l := range c.Lines {
+		pts := downsample(l.Points, chartMaxPts)
+		axis := 0
+		if l.Right {
+			axis = 1
+		}
+		for _, p := range pts {
+			if math.IsNaN(p.Y) || math.IsInf(p.Y, 0) || (c.XLog && p.X <= 0) {
+				continue
+			}
+			xmin, xmax = math.Min(xmin, p.X), math.Max(xmax, p.X)
+			ymin[axis], ymax[axis] = math.Min(ymin[axis], p.Y), math.Max(ymax[axis], p.Y)
+		}
+		lines[i] = pts
+	}
+	if math.IsInf(xmin, 1) {
+		b.WriteString(`<text x="50%" y="50%" text-anchor="middle" fill="#999">no data</text></svg>`)
+		return template.HTML(b.String())
+	}
+	if len(c.XCategories) > 0 {
+		xmin, xmax = -0.5, float64(len(c.XCategories))-0.5
+	}
+
+	// Axes
+	var xs axisScale
+	switch {
+	case c.XLog:
+		xs = logScale(xmin, xmax, left, right)
+	case c.XTime:
+		xs = timeScale(xmin, xmax, left, right)
+	case len(c.XCategories) > 0:
+		xs = linearScale(xmin, xmax, left, right, false)
+		xs.ticks = nil
+		for i, cat := range c.XCategories {
+			xs.ticks = append(xs.ticks, axisTick{V: float64(i), Label: cat})
+		}
+	default:
+		xs = linearScale(xmin, xmax, left, right, true)
+	}
+	var ys [2]axisScale
+	for axis := range ys {
+		lo, hi := ymin[axis], ymax[axis]
+		if math.IsInf(lo, 1) {
+			lo, hi = 0, 1
+		}
+		if c.YMin != nil {
+			lo = math.Min(*c.YMin, lo)
+		}
+		if axis == 0 && c.YMax != nil {
+			hi = math.Max(*c.YMax, hi)
+		}
+		ys[axis] = linearScale(lo, hi, bottom, top, c.YMin == nil || c.YMax == nil)
+	}
+
+	// Grid and tick labels
+	b.WriteString(`<g stroke="#e5e5e5" stroke-width="1">`)
+	for _, t := range ys[0].ticks {
+		y := ys[0].pos(t.V)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`, left, y, right, y)
+	}
+	for _, t := range xs.ticks {
+		x := xs.pos(t.V)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`, x, top, x, bottom)
+	}
+	b.WriteString(`</g><g fill="#666">`)
+	for _, t := range ys[0].ticks {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end" dy="4">%s</text>`, left-6, ys[0].pos(t.V), html.EscapeString(t.Label))
+	}
+	if hasRight {
+		for _, t := range ys[1].ticks {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" dy="4">%s</text>`, right+6, ys[1].pos(t.V), html.EscapeString(t.Label))
+		}
+	}
+	for _, t := range xs.ticks {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, xs.pos(t.V), bottom+16, html.EscapeString(t.Label))
+	}
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, (left+right)/2, chartH-8, html.EscapeString(c.XTitle))
+	fmt.Fprintf(&b, `<text transform="translate(14 %.1f) rotate(-90)" text-anchor="middle">%s</text>`, (top+bottom)/2, html.EscapeString(c.YTitle))
+	if hasRight {
+		fmt.Fprintf(&b, `<text transform="translate(%d %.1f) rotate(90)" text-anchor="middle">%s</text>`, chartW-14, (top+bottom)/2, html.EscapeString(c.Y2Title))
+	}
+	b.WriteString(`</g>`)
+	fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="#ccc"/>`, left, top, right-left, bottom-top)
+
+	// Lines, clipped to the plot area. Charts share one document, so clip
+	// path ids are numbered
+	clip := fmt.Sprintf("chart-clip-%d", chartSeq.Add(1))
+	fmt.Fprintf(&b, `<clipPath id="%s"><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"/></clipPath>`,
+		clip, left, top-2, right-left, bottom-top+4)
+	fmt.Fprintf(&b, `<g clip-path="url(#%s)" fill="none" stroke-width="1.5" stroke-linejoin="round">`, clip)
+	for i, l := range c.Lines {
+		color := chartColors[i%len(chartColors)]
+		ya := ys[0]
+		if l.Right {
+			ya = ys[1]
+		}
+		var d strings.Builder
+		pen := false
+		for _, p := range lines[i] {
+			if math.IsNaN(p.Y) || math.IsInf(p.Y, 0) || (c.XLog && p.X <= 0) {
+				pen = false
+				continue
+			}
+			cmd := 'L'
+			if !pen {
+				cmd = 'M'
+			}
+			fmt.Fprintf(&d, "%c%.1f %.1f", cmd, xs.pos(p.X), ya.pos(p.Y))
+			pen = true
+		}
+		dash := ""
+		if l.Dashed {
+			dash = ` stroke-dasharray="6 4"`
+		}
+		fmt.Fprintf(&b, `<path d="%s" stroke="%s"%s/>`, d.String(), color, dash)
+		if l.Markers {
+			for _, p := range lines[i] {
+				if math.IsNaN(p.Y) || math.IsInf(p.Y, 0) || (c.XLog && p.X <= 0) {
+					continue
+				}
+				fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s" stroke="none"><title>%s: %s</title></circle>`,
+					xs.pos(p.X), ya.pos(p.Y), color, html.EscapeString(l.Label), formatTick(p.Y, 0.001))
+			}
+		}
+	}
+	b.WriteString(`</g>`)
+
+	// Legend, two rows at most
+	x, y := left, 12.0
+	for i, l := range c.Lines {
+		color := chartColors[i%len(chartColors)]
+		w := 22 + 6.5*float64(len(l.Label))
+		if x+w > right && x > left {
+			if y > 12 {
+				break // Out of room, remaining lines stay unlabelled
+			}
+			x, y = left, y+15
+		}
+		dash := ""
+		if l.Dashed {
+			dash = ` stroke-dasharray="4 2"`
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="3"%s/>`, x, y, x+14, y, color, dash)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" fill="#333">%s</text>`, x+18, y+4, html.EscapeString(l.Label))
+		x += w + 10
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// downsample keeps at most max points by stride, always keeping the last
+func downsample(pts []ChartPoint, max int) []ChartPoint {
+	if len(pts) <= max {
+		return pts
+	}
+	step := len(pts)/max + 1
+	out := make([]ChartPoint, 0, max+1)
+	for i := 0; i < len(pts); i += step {
+		out = append(out, pts[i])
+	}
+	if last := pts[len(pts)-1]; out[len(out)-1] != last {
+		out = append(out, last)
+	}
+	return out
+}
+
+type axisTick struct {
+	V     float64
+	Label string
+}
+
+// axisScale maps data values to pixels
+type axisScale struct {
+	lo, hi float64 // Data range (log10 for log scales)
+	p0, p1 float64 // Pixel range
+	log    bool
+	ticks  []axisTick
+}
+
+func (s axisScale) pos(v float64) float64 {
+	if s.log {
+		v = math.Log10(v)
+	}
+	if s.hi == s.lo {
+		return (s.p0 + s.p1) / 2
+	}
+	return s.p0 + (v-s.lo)/(s.hi-s.lo)*(s.p1-s.p0)
+}
+
+// linearScale covers [lo, hi] with nice ticks; nice widens the range to
+// the outer ticks
+func linearScale(lo, hi, p0, p1 float64, nice bool) axisScale {
+	if hi == lo {
+		if lo == 0 {
+			hi = 1
+		} else {
+			lo, hi = lo-math.Abs(lo)/2, hi+math.Abs(hi)/2
+		}
+	}
+	step := niceStep((hi - lo) / chartTickNum)
+	if nice {
+		lo, hi = math.Floor(lo/step)*step, math.Ceil(hi/step)*step
+	}
+	s := axisScale{lo: lo, hi: hi, p0: p0, p1: p1}
+	first := math.Ceil(lo/step - 1e-9)
+	for i := 0.0; (first+i)*step <= hi+step*1e-9; i++ {
+		v := (first + i) * step
+		s.ticks = append(s.ticks, axisTick{V: v, Label: formatTick(v, step)})
+	}
+	return s
+}
+
+// logScale covers [lo, hi] in whole decades with 1-2-5 ticks
+func logScale(lo, hi, p0, p1 float64) axisScale {
+	dlo, dhi := math.Floor(math.Log10(lo)), math.Ceil(math.Log10(hi))
+	if dhi == dlo {
+		dhi++
+	}
+	s := axisScale{lo: dlo, hi: dhi, p0: p0, p1: p1, log: true}
+	for d := dlo; d <= dhi; d++ {
+		for _, m := range []float64{1, 2, 5} {
+			v := m * math.Pow(10, d)
+			if math.Log10(v) > dhi+1e-9 {
+				break
+			}
+			s.ticks = append(s.ticks, axisTick{V: v, Label: formatTick(v, v)})
+		}
+	}
+	return s
+}
+
+// timeScale labels Unix seconds as wall clock times. A single point gets a
+// fixed ±1s axis; linearScale's ±|v|/2 would span decades of Unix time
+func timeScale(lo, hi, p0, p1 float64) axisScale {
+	if hi == lo {
+		lo, hi = lo-1, hi+1
+	}
+	s := linearScale(lo, hi, p0, p1, false)
+	layout := "15:04:05"
+	if hi-lo > 2*86400 {
+		layout = "01-02 15:04"
+	}
+	for i := range s.ticks {
+		s.ticks[i].Label = time.Unix(int64(s.ticks[i].V), 0).Format(layout)
+	}
+	return s
+}
+
+// niceStep rounds a raw tick step to 1, 2 or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	if raw <= 0 || math.IsNaN(raw) || math.IsInf(raw, 0) {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / mag; {
+	case f <= 1:
+		return mag
+	case f <= 2:
+		return 2 * mag
+	case f <= 5:
+		return 5 * mag
+	default:
+		return 10 * mag
+	}
+}
+
+// formatTick prints v with as many decimals as step needs
+func formatTick(v, step float64) string {
+	dec := 0
+	if step > 0 && step < 1 {
+		dec = int(math.Ceil(-math.Log10(step)))
+	}
+	if math.Abs(v) >= 1e6 && dec == 0 {
+		return strconv.FormatFloat(v/1e6, 'f', -1, 64) + "M"
+	}
+	return strconv.FormatFloat(v, 'f', dec, 64)
+}
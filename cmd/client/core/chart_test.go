@@ -0,0 +1,103 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestNiceStep(t *testing.T) {
+	tests := []struct {
+		raw, want float64
+	}{
+		{0, 1},
+		{-3, 1},
+		{math.NaN(), 1},
+		{math.Inf(1), 1},
+		{1, 1},
+		{0.001, 0.001},
+		{0.15, 0.2},
+		{0.3, 0.5},
+		{3, 5},
+		{7, 10},
+		{45, 50},
+		{1500, 2000},
+		{2e6, 2e6},
+	}
+	for _, tt := range tests {
+		if got := niceStep(tt.raw); !approxEqual(got, tt.want) {
+			t.Errorf("niceStep(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestLinearScale(t *testing.T) {
+	tests := []struct {
+		name           string
+		lo, hi         float64
+		nice           bool
+		wantLo, wantHi float64
+		wantTicks      []string
+	}{
+		{"zero range at zero", 0, 0, true, 0, 1, []string{"0.0", "0.2", "0.4", "0.6", "0.8", "1.0"}},
+		{"round range", 0, 100, true, 0, 100, []string{"0", "20", "40", "60", "80", "100"}},
+		{"nice widens to steps", 3, 97, true, 0, 100, []string{"0", "20", "40", "60", "80", "100"}},
+		{"not nice keeps range", 3, 97, false, 3, 97, []string{"20", "40", "60", "80"}},
+		{"single positive value", 10, 10, true, 4, 16, []string{"4", "6", "8", "10", "12", "14", "16"}},
+		{"single negative value", -10, -10, true, -16, -4, []string{"-16", "-14", "-12", "-10", "-8", "-6", "-4"}},
+		{"millions", 0, 6e6, true, 0, 6e6, []string{"0", "1M", "2M", "3M", "4M", "5M", "6M"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := linearScale(tt.lo, tt.hi, 0, 100, tt.nice)
+			if !approxEqual(s.lo, tt.wantLo) || !approxEqual(s.hi, tt.wantHi) {
+				t.Errorf("range = [%v, %v], want [%v, %v]", s.lo, s.hi, tt.wantLo, tt.wantHi)
+			}
+			var labels []string
+			for _, tk := range s.ticks {
+				labels = append(labels, tk.Label)
+			}
+			if !slices.Equal(labels, tt.wantTicks) {
+				t.Errorf("ticks = %v, want %v", labels, tt.wantTicks)
+			}
+		})
+	}
+}
+
+func TestTimeScale(t *testing.T) {
+	const ts = 1_700_000_000
+	tests := []struct {
+		name       string
+		lo, hi     float64
+		wantSpan   float64 // hi - lo of the axis
+		wantLayout string
+	}{
+		{"single point widens by one second", ts, ts, 2, "15:04:05"},
+		{"one minute", ts, ts + 60, 60, "15:04:05"},
+		{"over two days shows the date", ts, ts + 3*86400, 3 * 86400, "01-02 15:04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := timeScale(tt.lo, tt.hi, 0, 100)
+			if span := s.hi - s.lo; span != tt.wantSpan {
+				t.Errorf("span = %vs, want %vs", span, tt.wantSpan)
+			}
+			if len(s.ticks) == 0 {
+				t.Fatal("no ticks")
+			}
+			for _, tk := range s.ticks {
+				if tk.V < s.lo || tk.V > s.hi {
+					t.Errorf("tick %v outside [%v, %v]", tk.V, s.lo, s.hi)
+				}
+				if want := time.Unix(int64(tk.V), 0).Format(tt.wantLayout); tk.Label != want {
+					t.Errorf("tick label = %q, want %q", tk.Label, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WriteMarkdown appends a GitHub-flavoured summary of the run: headline
+// metrics, threshold checks and the largest error classes. Appending lets
+// several runs share one file such as $GITHUB_STEP_SUMMARY
+func WriteMarkdown(path string, label string, s Summary, logger *Logger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+
+	var b strings.Builder
+	title := label
+	if title == "" {
+		title = "Benchmark"
+	}
+	status := ""
+	if len(s.Checks) > 0 {
+		status = " ✅"
+		if s.ChecksFailed() {
+			status = " ❌"
+		}
+	}
+	fmt.Fprintf(&b, "### %s%s\n\n", mdEscape(title), status)
+	if p := s.Meta["protocol"]; p != "" {
+		fmt.Fprintf(&b, "Protocol **%s**", mdEscape(p))
+		if sc := s.Meta["scenario"]; sc != "" {
+			fmt.Fprintf(&b, " · scenario `%s`", sc)
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("| samples | ok % | timeout % | rps | p50 ms | p90 ms | p95 ms | p99 ms | mean ms | max ms |\n")
+	b.WriteString("|---:|---:|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %.3f | %.3f | %.2f | %.3f | %.3f | %.3f | %.3f | %.3f | %.3f |\n\n",
+		s.Samples, s.OKRatePct, s.TimeoutPct, s.RPS, s.P50ms, s.P90ms, s.P95ms, s.P99ms, s.Meanms, s.Maxms)
+	if s.WindowFallback {
+		fmt.Fprintf(&b, "⚠️ Measurement window empty, stats include the warm-up (%s)\n\n", mdEscape(s.Window))
+	}
+
+	if len(s.Classes) > 0 {
+		b.WriteString("| class | samples | ok % | rps | p50 ms | p99 ms |\n")
+		b.WriteString("|---|---:|---:|---:|---:|---:|\n")
+		for _, c := range s.Classes {
+			fmt.Fprintf(&b, "| %s | %d | %.3f | %.2f | %.3f | %.3f |\n", mdEscape(c.Class), c.Samples, c.OKRatePct, c.RPS, c.P50ms, c.P99ms)
+		}
+		b.WriteString("\n")
+	}
+
+	if s.Capacity.Active() {
+		if s.Capacity.KneeRPS > 0 {
+			fmt.Fprintf(&b, "Capacity knee: **%d rps** (p99 %.3f ms", s.Capacity.KneeRPS, s.Capacity.KneeP99ms)
+			if s.Capacity.Capped {
+				b.WriteString(", capped at --capacity-max")
+			}
+			b.WriteString(")\n\n")
+		} else {
+			b.WriteString("Capacity knee: **none** (first step failed the SLO)\n\n")
+		}
+	}
+
+	if len(s.Checks) > 0 {
+		b.WriteString("| threshold | actual | result |\n")
+		b.WriteString("|---|---:|:---:|\n")
+		for _, c := range s.Checks {
+			actual, result := formatTick(c.Actual, 0.001), "✅ pass"
+			if c.Error != "" {
+				actual = mdEscape(c.Error)
+			}
+			if !c.Pass {
+				result = "❌ fail"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", c.Name, actual, result)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.ErrorClasses) > 0 {
+		type kv struct {
+			k string
+			v int
+		}
+		var errs []kv
+		for k, v := range s.ErrorClasses {
+			errs = append(errs, kv{k, v})
+		}
+		sort.Slice(errs, func(i, j int) bool {
+			if errs[i].v != errs[j].v {
+				return errs[i].v > errs[j].v
+			}
+			return errs[i].k < errs[j].k
+		})
+		b.WriteString("<details><summary>Errors</summary>\n\n| class | count |\n|---|---:|\n")
+		for _, e := range errs {
+			fmt.Fprintf(&b, "| %s | %d |\n", mdEscape(e.k), e.v)
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return err
+	}
+
+	logger.Info("Markdown written: %s", path)
+	return nil
+}
+
+// mdEscape keeps free text from breaking a Markdown table
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// WriteJUnit writes the threshold checks as a JUnit XML report, one test
+// case per check. Without thresholds the run is a single case that passes
+// when it produced successful samples
+func WriteJUnit(path string, label string, s Summary, logger *Logger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+
+	name := label
+	if name == "" {
+		name = "benchmark"
+	}
+	metrics := fmt.Sprintf("samples=%d ok_rate_pct=%.3f rps=%.2f p50_ms=%.3f p90_ms=%.3f p99_ms=%.3f max_ms=%.3f",
+		s.Samples, s.OKRatePct, s.RPS, s.P50ms, s.P90ms, s.P99ms, s.Maxms)
+
+	suite := junitSuite{Name: name, Time: fmt.Sprintf("%.3f", s.DurationS)}
+	keys := make([]string, 0, len(s.Meta))
+	for k := range s.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		suite.Properties = append(suite.Properties, junitProperty{Name: k, Value: s.Meta[k]})
+	}
+
+	if len(s.Checks) == 0 {
+		c := junitCase{Name: "run completed", Classname: name, SystemOut: metrics}
+		if s.Samples == 0 || s.OKRatePct == 0 {
+			c.Failure = &junitFailure{Message: "no successful samples", Type: "run"}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	for _, ch := range s.Checks {
+		c := junitCase{Name: ch.Name, Classname: name, SystemOut: metrics}
+		if !ch.Pass {
+			c.Failure = &junitFailure{Message: ch.Message(), Type: "threshold"}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suite.Tests = len(suite.Cases)
+	for _, c := range suite.Cases {
+		if c.Failure != nil {
+			suite.Failures++
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	doc := junitSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitSuite{suite}}
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if _, err := f.WriteString("\n"); err != nil {
+		return err
+	}
+
+	logger.Info("JUnit written: %s", path)
+	return nil
+}
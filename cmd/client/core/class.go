@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"sort"
+)
+
+type classKey struct{}
+
+// WithClass tags requests made with ctx with a request class (e.g. payload size)
+func WithClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, classKey{}, class)
+}
+
+func classFrom(ctx context.Context) string {
+	c, _ := ctx.Value(classKey{}).(string)
+	return c
+}
+
+// ClassSummary holds the stats of one request class
+type ClassSummary struct {
+	Class     string
+	Samples   int
+	OKRatePct float64
+	RPS       float64
+	P50ms     float64
+	P90ms     float64
+	P95ms     float64
+	P99ms     float64
+	Meanms    float64
+	Maxms     float64
+	CDF_X_ms  []float64
+	CDF_Y     []float64
+	THR_Ts    []int64
+	THR_Val   []int
+}
+
+// summarizeClasses summarizes each request class separately, ordered by name
+func summarizeClasses(all []Record) []ClassSummary {
+	groups := make(map[string][]Record)
+	for _, r := range all {
+		if r.Class != "" {
+			groups[r.Class] = append(groups[r.Class], r)
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(groups))
+	for k := range groups {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	out := make([]ClassSummary, 0, len(names))
+	for _, name := range names {
+		s := summarize(groups[name])
+		out = append(out, ClassSummary{
+			Class:     name,
+			Samples:   s.Samples,
+			OKRatePct: s.OKRatePct,
+			RPS:       s.RPS,
+			P50ms:     s.P50ms,
+			P90ms:     s.P90ms,
+			P95ms:     s.P95ms,
+			P99ms:     s.P99ms,
+			Meanms:    s.Meanms,
+			Maxms:     s.Maxms,
+			CDF_X_ms:  s.CDF_X_ms,
+			CDF_Y:     s.CDF_Y,
+			THR_Ts:    s.THR_Ts,
+			THR_Val:   s.THR_Val,
+		})
+	}
+	return out
+}
+
+// Class returns the stats of a request class by name
+func (s Summary) Class(name string) ClassSummary {
+	for _, c := range s.Classes {
+		if c.Class == name {
+			return c
+		}
+	}
+	return ClassSummary{Class: name}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSummarizeClasses(t *testing.T) {
+	const start = int64(1_700_000_000) * 1e9
+	var rs []Record
+	add := func(class string, n int, lat time.Duration, failures int) {
+		for i := 0; i < n; i++ {
+			rs = append(rs, Record{
+				TsUnixNS:  start + int64(len(rs))*int64(time.Millisecond),
+				LatencyNS: lat.Nanoseconds(),
+				OK:        i >= failures,
+				Class:     class,
+			})
+		}
+	}
+	add("small", 10, time.Millisecond, 0)
+	add("large", 10, 20*time.Millisecond, 5)
+	add("", 4, time.Second, 0) // Unclassified, only in the headline stats
+
+	s := Summarize(rs)
+	if s.Samples != 24 {
+		t.Errorf("headline samples = %d, want 24", s.Samples)
+	}
+	tests := []struct {
+		class   string
+		samples int
+		okPct   float64
+		p50ms   float64
+	}{
+		{"large", 10, 50, 20},
+		{"small", 10, 100, 1},
+	}
+	if len(s.Classes) != len(tests) {
+		t.Fatalf("got %d classes, want %d", len(s.Classes), len(tests))
+	}
+	for i, tt := range tests {
+		c := s.Classes[i]
+		if c.Class != tt.class || c.Samples != tt.samples || c.OKRatePct != tt.okPct || c.P50ms != tt.p50ms {
+			t.Errorf("class %d = %s samples=%d ok=%.1f%% p50=%.3fms, want %s samples=%d ok=%.1f%% p50=%.3fms",
+				i, c.Class, c.Samples, c.OKRatePct, c.P50ms, tt.class, tt.samples, tt.okPct, tt.p50ms)
+		}
+		if got := s.Class(tt.class); got.Samples != tt.samples {
+			t.Errorf("Class(%q).Samples = %d, want %d", tt.class, got.Samples, tt.samples)
+		}
+	}
+	if got := s.Class("medium"); got.Class != "medium" || got.Samples != 0 {
+		t.Errorf("Class(medium) = %+v, want an empty summary", got)
+	}
+}
+
+func TestSummarizeClassesUnclassified(t *testing.T) {
+	s := Summarize([]Record{{TsUnixNS: 1, LatencyNS: 1, OK: true}})
+	if s.Classes != nil {
+		t.Errorf("Classes = %v, want nil without tagged records", s.Classes)
+	}
+}
+
+func TestWithClass(t *testing.T) {
+	ctx := WithClass(context.Background(), "large")
+	if got := classFrom(ctx); got != "large" {
+		t.Errorf("classFrom = %q, want large", got)
+	}
+	if got := classFrom(context.Background()); got != "" {
+		t.Errorf("classFrom without a class = %q, want empty", got)
+	}
+}
@@ -1,34 +1,137 @@
 package core
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
+	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
 	"golang.org/x/net/http2"
+
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // NewHTTPClient creates an HTTP client (H2 or H3) with TLS config
 // Returns the HTTP client and a cleanup function
 func NewHTTPClient(useH3 bool, insecure bool, logger *Logger) (*http.Client, func()) {
+	return NewHTTPClientWithConfig(useH3, insecure, &transport.Config{}, logger)
+}
+
+// NewHTTPClientWithConfig creates an HTTP client (H2 or H3) with transport tuning applied
+// Returns the HTTP client and a cleanup function
+func NewHTTPClientWithConfig(useH3 bool, insecure bool, cfg *transport.Config, logger *Logger) (*http.Client, func()) {
+	client, closer := BuildHTTPClient(useH3, insecure, cfg)
+	if useH3 {
+		logger.Info("HTTP client initialized: HTTP/3 (QUIC) insecure=%v", insecure)
+	} else {
+		logger.Info("HTTP client initialized: HTTP/2 (TCP) insecure=%v", insecure)
+	}
+	return client, closer
+}
+
+// quicEnvOnce exports the QUIC GSO/ECN environment before the first HTTP/3
+// client of the process; every client comes from the same flags
+var quicEnvOnce sync.Once
+
+// BuildHTTPClient creates an HTTP client without logging, for scenarios
+// that open a new connection per cycle or per request
+func BuildHTTPClient(useH3 bool, insecure bool, cfg *transport.Config) (*http.Client, func()) {
 	tlsCfg := &tls.Config{
 		MinVersion:         tls.VersionTLS13,
 		InsecureSkipVerify: insecure,
+		KeyLogWriter:       cfg.KeyLogWriter(),
 	}
 
 	if useH3 {
-		tr := &http3.Transport{TLSClientConfig: tlsCfg}
-		logger.Info("HTTP client initialized: HTTP/3 (QUIC) insecure=%v", insecure)
+		quicEnvOnce.Do(cfg.ApplyQUICEnv)
+		tr := &http3.Transport{TLSClientConfig: tlsCfg, QUICConfig: cfg.QUICConfig()}
+		tr.Dial = func(ctx context.Context, addr string, tlsCfg *tls.Config, qcfg *quic.Config) (*quic.Conn, error) {
+			// Bound the QUIC handshake independently of the request deadline
+			if cfg.ConnectTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+				defer cancel()
+			}
+			conn, err := quic.DialAddrEarly(ctx, addr, tlsCfg, qcfg)
+			if err != nil {
+				return nil, err
+			}
+			openConns.Add(1)
+			go func() {
+				<-conn.Context().Done()
+				openConns.Add(-1)
+			}()
+			return conn, nil
+		}
 		return &http.Client{Transport: tr, Timeout: 0}, func() { tr.CloseIdleConnections() }
 	}
 
+	// Connect timeout bounds the TCP dial, and the TLS handshake unless set separately
+	handshakeTimeout := cfg.HandshakeTimeout
+	if handshakeTimeout == 0 {
+		handshakeTimeout = cfg.ConnectTimeout
+	}
+	dialer := &net.Dialer{Timeout: cfg.ConnectTimeout, KeepAlive: cfg.KeepAlivePeriod, Control: cfg.Control()}
 	h2 := &http.Transport{
-		TLSClientConfig:   tlsCfg,
-		ForceAttemptHTTP2: true,
+		TLSClientConfig:     tlsCfg,
+		ForceAttemptHTTP2:   true,
+		DialContext:         countingDial(dialer.DialContext),
+		TLSHandshakeTimeout: handshakeTimeout,
+		IdleConnTimeout:     cfg.IdleTimeout,
+		HTTP2:               cfg.HTTP2Config(),
 	}
 	_ = http2.ConfigureTransport(h2)
-	logger.Info("HTTP client initialized: HTTP/2 (TCP) insecure=%v", insecure)
-	return &http.Client{Transport: h2, Timeout: 0}, func() {}
+	return &http.Client{Transport: h2, Timeout: 0}, func() { h2.CloseIdleConnections() }
+}
+
+// openConns counts client connections opened by BuildHTTPClient and not yet closed
+var openConns atomic.Int64
+
+// OpenConnections returns the number of client connections currently open
+func OpenConnections() int64 {
+	return openConns.Load()
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// countingDial wraps dial so connections are tracked in openConns
+func countingDial(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		openConns.Add(1)
+		return &countedConn{Conn: c}, nil
+	}
+}
+
+type countedConn struct {
+	net.Conn
+	once   sync.Once
+	closed atomic.Bool
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() {
+		c.closed.Store(true)
+		openConns.Add(-1)
+	})
+	return c.Conn.Close()
+}
+
+// connClosed reports whether a TCP connection dialed by BuildHTTPClient,
+// possibly wrapped in TLS, has been closed
+func connClosed(c net.Conn) bool {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	cc, ok := c.(*countedConn)
+	return ok && cc.closed.Load()
 }
 
 // ProtocolName returns human-readable protocol name
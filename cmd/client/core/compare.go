@@ -0,0 +1,272 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ReadResult loads a result written by WriteJSON
+func ReadResult(path string) (Result, error) {
+	var r Result
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// compareMetric is one row of the comparison delta table
+type compareMetric struct {
+	Name         string
+	Format       string
+	HigherBetter bool
+	f            func(s Summary) float64
+}
+
+var compareMetrics = []compareMetric{
+	{"samples", "%.0f", true, func(s Summary) float64 { return float64(s.Samples) }},
+	{"ok_rate_%", "%.3f", true, func(s Summary) float64 { return s.OKRatePct }},
+	{"timeout_%", "%.3f", false, func(s Summary) float64 { return s.TimeoutPct }},
+	{"rps", "%.2f", true, func(s Summary) float64 { return s.RPS }},
+	{"p50_ms", "%.3f", false, func(s Summary) float64 { return s.P50ms }},
+	{"p90_ms", "%.3f", false, func(s Summary) float64 { return s.P90ms }},
+	{"p95_ms", "%.3f", false, func(s Summary) float64 { return s.P95ms }},
+	{"p99_ms", "%.3f", false, func(s Summary) float64 { return s.P99ms }},
+	{"mean_ms", "%.3f", false, func(s Summary) float64 { return s.Meanms }},
+	{"max_ms", "%.3f", false, func(s Summary) float64 { return s.Maxms }},
+}
+
+// compareCell is one run's value of a metric, with its change against the
+// first (baseline) run
+type compareCell struct {
+	Value   string
+	Delta   string // "+12.3%", empty for the baseline or a zero baseline
+	Verdict string // "better", "worse" or empty
+}
+
+// WriteCompareHTML renders two or more runs on one page: overlaid latency
+// CDFs, throughput and latency over time, a delta table against the first
+// run and the run configurations side by side
+func WriteCompareHTML(path string, title string, runs []Result, logger *Logger) error {
+	if len(runs) < 2 {
+		return fmt.Errorf("compare needs at least two runs, got %d", len(runs))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+
+	labels := make([]string, len(runs))
+	for i, r := range runs {
+		labels[i] = r.Label
+		if labels[i] == "" {
+			labels[i] = fmt.Sprintf("run %d", i+1)
+		}
+	}
+
+	// Delta table
+	type metricRow struct {
+		Name  string
+		Cells []compareCell
+	}
+	var metrics []metricRow
+	for _, m := range compareMetrics {
+		row := metricRow{Name: m.Name}
+		base := m.f(runs[0].Summary)
+		for i, r := range runs {
+			v := m.f(r.Summary)
+			c := compareCell{Value: fmt.Sprintf(m.Format, v)}
+			if i > 0 && base != 0 {
+				d := (v - base) / math.Abs(base) * 100
+				c.Delta = fmt.Sprintf("%+.1f%%", d)
+				switch {
+				case math.Abs(d) < 0.05:
+				case (d > 0) == m.HigherBetter:
+					c.Verdict = "better"
+				default:
+					c.Verdict = "worse"
+				}
+			}
+			row.Cells = append(row.Cells, c)
+		}
+		metrics = append(metrics, row)
+	}
+
+	// Configurations side by side, differing values flagged
+	type configRow struct {
+		Key    string
+		Values []string
+		Differ bool
+	}
+	keySet := map[string]bool{}
+	for _, r := range runs {
+		for k := range r.Summary.Meta {
+			keySet[k] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var configs []configRow
+	for _, k := range keys {
+		row := configRow{Key: k}
+		for i, r := range runs {
+			v, ok := r.Summary.Meta[k]
+			if !ok {
+				v = "–"
+			}
+			row.Values = append(row.Values, v)
+			row.Differ = row.Differ || (i > 0 && v != row.Values[0])
+		}
+		configs = append(configs, row)
+	}
+
+	data := struct {
+		Title   string
+		Labels  []string
+		Metrics []metricRow
+		Configs []configRow
+		Charts  map[string]template.HTML
+	}{
+		Title:   title,
+		Labels:  labels,
+		Metrics: metrics,
+		Configs: configs,
+		Charts:  compareCharts(labels, runs),
+	}
+
+	t, err := template.New("compare").Parse(compareHTMLTemplate)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := t.Execute(f, data); err != nil {
+		return err
+	}
+
+	logger.Info("Comparison HTML written: %s", path)
+	return nil
+}
+
+// compareCharts overlays the runs. Runs happen at different times, so time
+// series are plotted against seconds since each run's start
+func compareCharts(labels []string, runs []Result) map[string]template.HTML {
+	cdf := Chart{XTitle: "Latency (ms, log scale)", YTitle: "CDF", XLog: true, YMin: Float(0), YMax: Float(1)}
+	thr := Chart{XTitle: "Seconds since start", YTitle: "Requests/sec", YMin: Float(0)}
+	p50 := Chart{XTitle: "Seconds since start", YTitle: "p50 latency (ms)", YMin: Float(0)}
+	p99 := Chart{XTitle: "Seconds since start", YTitle: "p99 latency (ms)", YMin: Float(0)}
+	for i, r := range runs {
+		s := r.Summary
+		var pts []ChartPoint
+		for j := range s.CDF_X_ms {
+			pts = append(pts, ChartPoint{X: s.CDF_X_ms[j], Y: s.CDF_Y[j]})
+		}
+		cdf.Lines = append(cdf.Lines, ChartLine{Label: labels[i], Points: pts})
+
+		pts = nil
+		for j := range s.THR_Ts {
+			pts = append(pts, ChartPoint{X: float64(s.THR_Ts[j] - s.THR_Ts[0]), Y: float64(s.THR_Val[j])})
+		}
+		thr.Lines = append(thr.Lines, ChartLine{Label: labels[i], Points: pts})
+
+		var lo, hi []ChartPoint
+		for _, p := range s.Series {
+			x := float64(p.TsUnixNS-s.Series[0].TsUnixNS) / 1e9
+			if p.Samples == 0 {
+				lo = append(lo, ChartPoint{X: x, Y: math.NaN()})
+				hi = append(hi, ChartPoint{X: x, Y: math.NaN()})
+				continue
+			}
+			lo = append(lo, ChartPoint{X: x, Y: p.P50ms})
+			hi = append(hi, ChartPoint{X: x, Y: p.P99ms})
+		}
+		p50.Lines = append(p50.Lines, ChartLine{Label: labels[i], Points: lo})
+		p99.Lines = append(p99.Lines, ChartLine{Label: labels[i], Points: hi})
+	}
+	return map[string]template.HTML{
+		"cdf": cdf.SVG(),
+		"thr": thr.SVG(),
+		"p50": p50.SVG(),
+		"p99": p99.SVG(),
+	}
+}
+
+const compareHTMLTemplate = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{ .Title }} – Benchmark Comparison</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; margin: 24px; }
+h1 { margin-bottom: 0; }
+.sub { color: #666; margin-top: 4px; }
+table { border-collapse: collapse; margin-top: 16px; }
+td, th { border: 1px solid #ddd; padding: 6px 10px; text-align: left; }
+.grid { display: grid; grid-template-columns: 1fr; gap: 16px; margin-top: 18px; }
+.chart { width: 100%; height: auto; }
+.better { color: #2e7d32; }
+.worse { color: #c62828; }
+.delta { font-size: 90%; margin-left: 6px; }
+.differ td { background: #fff8e1; }
+@media (min-width: 900px) { .grid { grid-template-columns: 1fr 1fr; } }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<div class="sub">{{ range $i, $l := .Labels }}{{ if $i }} vs {{ end }}{{ $l }}{{ end }} · deltas relative to {{ index .Labels 0 }}</div>
+
+<h2>Summary</h2>
+<table>
+<thead><tr><th>metric</th>{{ range .Labels }}<th>{{ . }}</th>{{ end }}</tr></thead>
+<tbody>
+{{ range .Metrics }}	<tr><td>{{ .Name }}</td>{{ range .Cells }}<td>{{ .Value }}{{ if .Delta }}<span class="delta {{ .Verdict }}">{{ .Delta }}</span>{{ end }}</td>{{ end }}</tr>
+{{ end }}</tbody>
+</table>
+
+<div class="grid">
+<div>
+	<h3>Latency CDF</h3>
+	{{ index .Charts "cdf" }}
+</div>
+<div>
+	<h3>Throughput per Second</h3>
+	{{ index .Charts "thr" }}
+</div>
+<div>
+	<h3>p50 Latency over Time</h3>
+	{{ index .Charts "p50" }}
+</div>
+<div>
+	<h3>p99 Latency over Time</h3>
+	{{ index .Charts "p99" }}
+</div>
+</div>
+
+{{ if .Configs }}
+<h2>Run Configuration</h2>
+<table>
+<thead><tr><th>key</th>{{ range .Labels }}<th>{{ . }}</th>{{ end }}</tr></thead>
+<tbody>
+{{ range .Configs }}	<tr{{ if .Differ }} class="differ"{{ end }}><td>{{ .Key }}</td>{{ range .Values }}<td>{{ . }}</td>{{ end }}</tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+</body>
+</html>
+`
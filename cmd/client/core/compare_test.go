@@ -0,0 +1,43 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompareHTML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compare.html")
+	runs := []Result{
+		{Label: "h2", Summary: Summary{Samples: 1000, RPS: 100, P99ms: 100}},
+		{Label: "h3", Summary: Summary{Samples: 1000, RPS: 120, P99ms: 150}},
+	}
+	if err := WriteCompareHTML(path, "h2 vs h3", runs, NewLogger(LogLevelQuiet)); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	for _, want := range []string{
+		// html/template escapes the sign
+		`<td>rps</td><td>100.00</td><td>120.00<span class="delta better">&#43;20.0%</span>`,
+		`<td>p99_ms</td><td>100.000</td><td>150.000<span class="delta worse">&#43;50.0%</span>`,
+		`<td>samples</td><td>1000</td><td>1000<span class="delta ">&#43;0.0%</span>`,
+		// Zero baseline has no delta
+		`<td>p50_ms</td><td>0.000</td><td>0.000</td>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %s", want)
+		}
+	}
+}
+
+func TestWriteCompareHTMLNeedsTwoRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compare.html")
+	if err := WriteCompareHTML(path, "one", []Result{{Label: "h2"}}, NewLogger(LogLevelQuiet)); err == nil {
+		t.Error("WriteCompareHTML with one run: want error")
+	}
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"syscall"
+
+	"connectrpc.com/connect"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+)
+
+// Error classes. Classes with a code are reported as "<class>:<code>",
+// e.g. "h3_error:H3_REQUEST_CANCELLED" or "connect:unavailable"
+const (
+	ErrClassCanceled         = "canceled"          // context.Canceled (run stopped, hedge lost)
+	ErrClassDeadline         = "deadline_exceeded" // context.DeadlineExceeded
+	ErrClassNetTimeout       = "net_timeout"       // net.Error with Timeout()
+	ErrClassConnRefused      = "conn_refused"      // ECONNREFUSED
+	ErrClassConnReset        = "conn_reset"        // ECONNRESET / EPIPE
+	ErrClassEOF              = "eof"               // connection closed mid-response
+	ErrClassTLS              = "tls"               // TLS handshake / certificate errors
+	ErrClassQUICIdleTimeout  = "quic_idle_timeout" // QUIC idle timeout close
+	ErrClassQUICHandshake    = "quic_handshake_timeout"
+	ErrClassQUICStatelessRst = "quic_stateless_reset"
+	ErrClassQUICVersion      = "quic_version_negotiation"
+	ErrClassQUICTransport    = "quic_transport"   // + transport error code
+	ErrClassQUICApplication  = "quic_application" // + application error code
+	ErrClassQUICStream       = "quic_stream"      // + stream error code
+	ErrClassH3               = "h3_error"         // + HTTP/3 error code
+	ErrClassH2Stream         = "h2_stream"        // + HTTP/2 error code
+	ErrClassH2GoAway         = "h2_goaway"        // + HTTP/2 error code
+	ErrClassH2Connection     = "h2_connection"    // + HTTP/2 error code
+	ErrClassConnect          = "connect"          // + connect code (no transport cause)
+	ErrClassOther            = "other"
+)
+
+// ClassifyError maps an error to its class in the taxonomy above.
+// Transport causes win over the connect code wrapping them, since
+// connect reports most transport failures as "unavailable" or "unknown"
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	// Context
+	if errors.Is(err, context.Canceled) {
+		return ErrClassCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrClassDeadline
+	}
+
+	// QUIC / HTTP/3
+	var idleErr *quic.IdleTimeoutError
+	var hsErr *quic.HandshakeTimeoutError
+	var resetErr *quic.StatelessResetError
+	var vnErr *quic.VersionNegotiationError
+	var trErr *quic.TransportError
+	var appErr *quic.ApplicationError
+	var streamErr *quic.StreamError
+	var h3Err *http3.Error
+	switch {
+	case errors.As(err, &idleErr):
+		return ErrClassQUICIdleTimeout
+	case errors.As(err, &hsErr):
+		return ErrClassQUICHandshake
+	case errors.As(err, &resetErr):
+		return ErrClassQUICStatelessRst
+	case errors.As(err, &vnErr):
+		return ErrClassQUICVersion
+	case errors.As(err, &h3Err):
+		return ErrClassH3 + ":" + h3Code(h3Err.ErrorCode)
+	case errors.As(err, &trErr):
+		if trErr.ErrorCode.IsCryptoError() {
+			return ErrClassTLS
+		}
+		return ErrClassQUICTransport + ":" + trErr.ErrorCode.String()
+	case errors.As(err, &appErr):
+		return ErrClassQUICApplication + ":" + h3Code(http3.ErrCode(appErr.ErrorCode))
+	case errors.As(err, &streamErr):
+		return ErrClassQUICStream + ":" + h3Code(http3.ErrCode(streamErr.ErrorCode))
+	}
+
+	// HTTP/2
+	var h2Stream http2.StreamError
+	var h2GoAway http2.GoAwayError
+	var h2Conn http2.ConnectionError
+	switch {
+	case errors.As(err, &h2Stream):
+		return ErrClassH2Stream + ":" + h2Stream.Code.String()
+	case errors.As(err, &h2GoAway):
+		return ErrClassH2GoAway + ":" + h2GoAway.ErrCode.String()
+	case errors.As(err, &h2Conn):
+		return ErrClassH2Connection + ":" + http2.ErrCode(h2Conn).String()
+	}
+
+	// TLS
+	var alertErr tls.AlertError
+	var recErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var authErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	if errors.As(err, &alertErr) || errors.As(err, &recErr) || errors.As(err, &certErr) ||
+		errors.As(err, &authErr) || errors.As(err, &hostErr) {
+		return ErrClassTLS
+	}
+
+	// Sockets
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrClassConnRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return ErrClassConnReset
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrClassEOF
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrClassNetTimeout
+	}
+
+	// Server-side connect codes
+	var cerr *connect.Error
+	if errors.As(err, &cerr) {
+		return ErrClassConnect + ":" + cerr.Code().String()
+	}
+	return ErrClassOther
+}
+
+// IsTimeoutClass reports whether an error class is a user-visible timeout:
+// the per-request deadline, a connect/handshake timeout or a dead path
+func IsTimeoutClass(class string) bool {
+	switch class {
+	case ErrClassDeadline, ErrClassNetTimeout, ErrClassQUICHandshake, ErrClassQUICIdleTimeout:
+		return true
+	}
+	return false
+}
+
+// ClassifyRequestError classifies a request failure and reports whether it
+// was a timeout. A fired request deadline wins over the transport error it
+// surfaces as (quic-go reports it as H3_REQUEST_CANCELLED, x/net as a cancel)
+func ClassifyRequestError(reqCtx context.Context, err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		return ErrClassDeadline, true
+	}
+	class := ClassifyError(err)
+	return class, IsTimeoutClass(class)
+}
+
+// h3Code names an HTTP/3 error code, falling back to hex for unknown codes
+func h3Code(c http3.ErrCode) string {
+	s := c.String()
+	if strings.HasPrefix(s, "unknown") {
+		return fmt.Sprintf("%#x", uint64(c))
+	}
+	return s
+}
+
+// ErrorClassCounts counts failed records per error class
+func ErrorClassCounts(all []Record) map[string]int {
+	var m map[string]int
+	for _, r := range all {
+		if r.OK || r.ErrClass == "" {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]int)
+		}
+		m[r.ErrClass]++
+	}
+	return m
+}
+
+// SortedErrorClasses returns error classes ordered by count, descending
+func SortedErrorClasses(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
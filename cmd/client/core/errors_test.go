@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+)
+
+func TestClassifyError(t *testing.T) {
+	opErr := func(err error) error { return &net.OpError{Op: "read", Net: "tcp", Err: err} }
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"canceled", fmt.Errorf("do: %w", context.Canceled), ErrClassCanceled},
+		{"deadline", fmt.Errorf("do: %w", context.DeadlineExceeded), ErrClassDeadline},
+
+		{"quic idle timeout", &quic.IdleTimeoutError{}, ErrClassQUICIdleTimeout},
+		{"quic handshake timeout", &quic.HandshakeTimeoutError{}, ErrClassQUICHandshake},
+		{"quic stateless reset", &quic.StatelessResetError{}, ErrClassQUICStatelessRst},
+		{"quic version negotiation", &quic.VersionNegotiationError{}, ErrClassQUICVersion},
+		{"quic transport", &quic.TransportError{ErrorCode: quic.FlowControlError}, "quic_transport:FLOW_CONTROL_ERROR"},
+		{"quic crypto", &quic.TransportError{ErrorCode: quic.TransportErrorCode(0x100 + 42)}, ErrClassTLS},
+		{"quic application", &quic.ApplicationError{ErrorCode: quic.ApplicationErrorCode(http3.ErrCodeInternalError)}, "quic_application:H3_INTERNAL_ERROR"},
+		{"quic stream", &quic.StreamError{ErrorCode: quic.StreamErrorCode(http3.ErrCodeRequestCanceled)}, "quic_stream:H3_REQUEST_CANCELLED"},
+		{"h3 error", &http3.Error{ErrorCode: http3.ErrCodeRequestCanceled}, "h3_error:H3_REQUEST_CANCELLED"},
+		{"h3 unknown code", &http3.Error{ErrorCode: 0x1f}, "h3_error:0x1f"},
+
+		{"h2 stream", http2.StreamError{StreamID: 1, Code: http2.ErrCodeRefusedStream}, "h2_stream:REFUSED_STREAM"},
+		{"h2 goaway", http2.GoAwayError{ErrCode: http2.ErrCodeEnhanceYourCalm}, "h2_goaway:ENHANCE_YOUR_CALM"},
+		{"h2 connection", http2.ConnectionError(http2.ErrCodeProtocol), "h2_connection:PROTOCOL_ERROR"},
+
+		{"tls alert", tls.AlertError(40), ErrClassTLS},
+		{"tls unknown authority", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, ErrClassTLS},
+		{"tls hostname", x509.HostnameError{Host: "example.com", Certificate: &x509.Certificate{}}, ErrClassTLS},
+
+		{"conn refused", opErr(syscall.ECONNREFUSED), ErrClassConnRefused},
+		{"conn reset", opErr(syscall.ECONNRESET), ErrClassConnReset},
+		{"broken pipe", opErr(syscall.EPIPE), ErrClassConnReset},
+		{"eof", fmt.Errorf("read body: %w", io.EOF), ErrClassEOF},
+		{"unexpected eof", io.ErrUnexpectedEOF, ErrClassEOF},
+		{"net timeout", opErr(os.ErrDeadlineExceeded), ErrClassNetTimeout},
+
+		{"connect code", connect.NewError(connect.CodeResourceExhausted, errors.New("busy")), "connect:resource_exhausted"},
+		{"transport cause wins over connect", connect.NewError(connect.CodeUnavailable, opErr(syscall.ECONNRESET)), ErrClassConnReset},
+		{"quic cause wins over connect", connect.NewError(connect.CodeUnknown, &quic.IdleTimeoutError{}), ErrClassQUICIdleTimeout},
+		{"other", errors.New("boom"), ErrClassOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyRequestError(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	h3Cancel := &http3.Error{ErrorCode: http3.ErrCodeRequestCanceled}
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		err         error
+		wantClass   string
+		wantTimeout bool
+	}{
+		{"no error", expired, nil, "", false},
+		{"deadline wins over transport error", expired, h3Cancel, ErrClassDeadline, true},
+		{"transport error", context.Background(), h3Cancel, "h3_error:H3_REQUEST_CANCELLED", false},
+		{"handshake timeout", context.Background(), &quic.HandshakeTimeoutError{}, ErrClassQUICHandshake, true},
+		{"idle timeout", context.Background(), &quic.IdleTimeoutError{}, ErrClassQUICIdleTimeout, true},
+		{"net timeout", context.Background(), os.ErrDeadlineExceeded, ErrClassNetTimeout, true},
+		{"refused", context.Background(), syscall.ECONNREFUSED, ErrClassConnRefused, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, timeout := ClassifyRequestError(tt.ctx, tt.err)
+			if class != tt.wantClass || timeout != tt.wantTimeout {
+				t.Errorf("ClassifyRequestError = %q, %v, want %q, %v", class, timeout, tt.wantClass, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestSortedErrorClasses(t *testing.T) {
+	rs := []Record{
+		{OK: true},
+		{ErrClass: ErrClassEOF}, {ErrClass: ErrClassEOF},
+		{ErrClass: ErrClassConnReset}, {ErrClass: ErrClassConnReset},
+		{ErrClass: ErrClassDeadline}, {ErrClass: ErrClassDeadline}, {ErrClass: ErrClassDeadline},
+		{ErrClass: ""},
+	}
+	m := ErrorClassCounts(rs)
+	got := SortedErrorClasses(m)
+	want := []string{ErrClassDeadline, ErrClassConnReset, ErrClassEOF}
+	if !slices.Equal(got, want) {
+		t.Errorf("SortedErrorClasses = %v, want %v", got, want)
+	}
+	if ErrorClassCounts([]Record{{OK: true}}) != nil {
+		t.Error("ErrorClassCounts of successful records should be nil")
+	}
+}
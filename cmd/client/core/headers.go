@@ -0,0 +1,367 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/quic-go/qpack"
+	"golang.org/x/net/http2/hpack"
+
+	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/transport"
+)
+
+// HeaderCompression estimates the encoded size of request header blocks.
+// Response headers are not measured. Nothing is read from the wire: every
+// header field a request writes is replayed through a mirror of the
+// protocol's encoder: per connection for HPACK, whose dynamic table makes
+// the output depend on earlier requests, and per request for quic-go's
+// QPACK encoder, which only uses the static table. HPACK sizes can be off
+// by the few-byte table size update the transport sends once the peer's
+// SETTINGS arrive, and by requests sent before a table size above the
+// 4096-byte default takes effect
+type HeaderCompression struct {
+	useH3     bool
+	tableSize uint32 // HPACK dynamic table size
+
+	mu     sync.Mutex
+	mirror map[net.Conn]*hpackMirror
+	stats  HeaderStats
+}
+
+// NewHeaderCompression creates a header compression meter for the protocol,
+// taking the HPACK table size from cfg
+func NewHeaderCompression(useH3 bool, cfg *transport.Config) *HeaderCompression {
+	h := &HeaderCompression{useH3: useH3, mirror: make(map[net.Conn]*hpackMirror)}
+	h.tableSize = uint32(cfg.HPACKEncoderTableSize())
+	h.stats.Codec = "HPACK"
+	h.stats.Estimated = true
+	h.stats.TableCapacity = h.tableSize
+	if useH3 {
+		h.stats.Codec = "QPACK"
+		h.stats.TableCapacity = transport.QPACKTableCapacity
+	}
+	return h
+}
+
+// HeaderStats summarizes request header compression over a run
+type HeaderStats struct {
+	Codec           string
+	Estimated       bool    // Sizes come from a client-side encoder mirror, not the wire
+	Blocks          uint64  // Header blocks (request attempts) encoded
+	Fields          uint64  // Header fields encoded, pseudo-headers included
+	RawBytes        uint64  // Sum of name+value lengths
+	EncodedBytes    uint64  // Encoded header block bytes, without frame headers
+	Ratio           float64 // EncodedBytes/RawBytes
+	SavingsPct      float64 // 100*(1-Ratio)
+	FirstBlockBytes uint64  // First block, before any dynamic table reuse
+	MinBlockBytes   uint64
+	MeanBlockBytes  float64
+	MaxBlockBytes   uint64
+	Indexed         uint64 // Fields sent as a table index
+	NameRef         uint64 // Fields with an indexed name and literal value
+	Literal         uint64 // Fields with a literal name and value
+	TableInserts    uint64 // Dynamic table insertions
+	TableEvictions  uint64 // Dynamic table evictions
+	TableCapacity   uint32 // Dynamic table capacity in bytes
+	TablePeakBytes  uint32 // Largest dynamic table occupancy seen
+}
+
+// Active reports whether any header block was measured
+func (s HeaderStats) Active() bool {
+	return s.Blocks > 0
+}
+
+// IndexedPct returns the share of fields sent as a table index
+func (s HeaderStats) IndexedPct() float64 {
+	if s.Fields == 0 {
+		return 0
+	}
+	return 100 * float64(s.Indexed) / float64(s.Fields)
+}
+
+// String formats the compression stats for logs
+func (s HeaderStats) String() string {
+	est := ""
+	if s.Estimated {
+		est = " (request headers, client-side estimate)"
+	}
+	return fmt.Sprintf("%s%s blocks=%d raw=%dB encoded=%dB ratio=%.3f savings=%.1f%% block_first=%dB block_mean=%.0fB block_min=%dB block_max=%dB indexed=%.1f%% name_ref=%d literal=%d table_inserts=%d table_evictions=%d table_peak=%d/%dB",
+		s.Codec, est, s.Blocks, s.RawBytes, s.EncodedBytes, s.Ratio, s.SavingsPct, s.FirstBlockBytes, s.MeanBlockBytes, s.MinBlockBytes, s.MaxBlockBytes,
+		s.IndexedPct(), s.NameRef, s.Literal, s.TableInserts, s.TableEvictions, s.TablePeakBytes, s.TableCapacity)
+}
+
+// Stats returns the compression stats measured so far
+func (h *HeaderCompression) Stats() HeaderStats {
+	h.mu.Lock()
+	s := h.stats
+	h.mu.Unlock()
+	if s.RawBytes > 0 {
+		s.Ratio = Round6(float64(s.EncodedBytes) / float64(s.RawBytes))
+		s.SavingsPct = Round6(100 * (1 - s.Ratio))
+	}
+	if s.Blocks > 0 {
+		s.MeanBlockBytes = Round6(float64(s.EncodedBytes) / float64(s.Blocks))
+	}
+	return s
+}
+
+// Wrap measures the request headers written by fn
+func (h *HeaderCompression) Wrap(fn RequestFunc) RequestFunc {
+	return func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		return fn(h.withTrace(ctx), cl, reqID)
+	}
+}
+
+// headerBlock accumulates one request's header block
+type headerBlock struct {
+	conn    net.Conn
+	buf     bytes.Buffer
+	qenc    *qpack.Encoder
+	fields  uint64
+	raw     uint64
+	encoded uint64
+	kinds   [3]uint64 // indexed, name ref, literal
+	inserts uint64
+	evicts  uint64
+	peak    uint32
+}
+
+func (h *HeaderCompression) withTrace(ctx context.Context) context.Context {
+	var mu sync.Mutex // Retries and hedges of one request share the trace
+	var b *headerBlock
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			mu.Lock()
+			b = &headerBlock{conn: info.Conn}
+			mu.Unlock()
+		},
+		WroteHeaderField: func(name string, values []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if b == nil {
+				return
+			}
+			for _, v := range values {
+				h.encode(b, name, v)
+			}
+		},
+		WroteHeaders: func() {
+			mu.Lock()
+			done := b
+			b = nil
+			mu.Unlock()
+			if done != nil && done.fields > 0 {
+				h.finish(done)
+			}
+		},
+	})
+}
+
+// encode replays one header field through the encoder mirror
+func (h *HeaderCompression) encode(b *headerBlock, name, value string) {
+	b.fields++
+	b.raw += uint64(len(name) + len(value))
+
+	if h.useH3 {
+		if b.qenc == nil {
+			b.qenc = qpack.NewEncoder(&b.buf)
+		}
+		before := b.buf.Len()
+		_ = b.qenc.WriteField(qpack.HeaderField{Name: name, Value: value})
+		out := b.buf.Bytes()[before:]
+		if before == 0 {
+			// Encoded field section prefix: Required Insert Count and Base
+			out = out[2:]
+		}
+		b.kinds[qpackKind(out)]++
+		return
+	}
+
+	h.mu.Lock()
+	m := h.mirror[b.conn]
+	if m == nil {
+		// A new connection: forget the ones the transport has closed
+		for c := range h.mirror {
+			if connClosed(c) {
+				delete(h.mirror, c)
+			}
+		}
+		m = newHPACKMirror(h.tableSize)
+		h.mirror[b.conn] = m
+	}
+	h.mu.Unlock()
+	m.encode(b, name, value)
+}
+
+func (h *HeaderCompression) finish(b *headerBlock) {
+	size := b.encoded
+	if h.useH3 {
+		size = uint64(b.buf.Len())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := &h.stats
+	if s.Blocks == 0 {
+		s.FirstBlockBytes = size
+		s.MinBlockBytes = size
+	}
+	s.Blocks++
+	s.Fields += b.fields
+	s.RawBytes += b.raw
+	s.EncodedBytes += size
+	s.MinBlockBytes = min(s.MinBlockBytes, size)
+	s.MaxBlockBytes = max(s.MaxBlockBytes, size)
+	s.Indexed += b.kinds[0]
+	s.NameRef += b.kinds[1]
+	s.Literal += b.kinds[2]
+	s.TableInserts += b.inserts
+	s.TableEvictions += b.evicts
+	s.TablePeakBytes = max(s.TablePeakBytes, b.peak)
+}
+
+// qpackKind classifies an encoded QPACK field line (RFC 9204 4.5)
+func qpackKind(out []byte) int {
+	switch {
+	case len(out) == 0:
+		return 2
+	case out[0]&0x80 != 0: // 1Txxxxxx indexed field line
+		return 0
+	case out[0]&0x40 != 0: // 01NTxxxx literal with name reference
+		return 1
+	default: // 001NHxxx literal with literal name
+		return 2
+	}
+}
+
+// hpackMirror replays a connection's request headers through an encoder
+// in the same state as the transport's, tracking the dynamic table
+type hpackMirror struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	enc     *hpack.Encoder
+	entries []uint32 // Dynamic table entry sizes, oldest first
+	size    uint32
+	maxSize uint32
+}
+
+func newHPACKMirror(tableSize uint32) *hpackMirror {
+	m := &hpackMirror{maxSize: tableSize}
+	m.enc = hpack.NewEncoder(&m.buf)
+	m.enc.SetMaxDynamicTableSizeLimit(tableSize)
+	if tableSize > transport.HPACKDefaultTableSize {
+		// Grown by the peer's SETTINGS_HEADER_TABLE_SIZE
+		m.enc.SetMaxDynamicTableSize(tableSize)
+	}
+	return m
+}
+
+func (m *hpackMirror) encode(b *headerBlock, name, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.buf.Reset()
+	_ = m.enc.WriteField(hpack.HeaderField{Name: name, Value: value})
+	out := m.buf.Bytes()
+	b.encoded += uint64(len(out))
+	out = skipTableSizeUpdate(out)
+
+	switch {
+	case len(out) == 0:
+		b.kinds[2]++
+	case out[0]&0x80 != 0: // 1xxxxxxx indexed
+		b.kinds[0]++
+	case out[0]&0x40 != 0: // 01xxxxxx literal with incremental indexing
+		if out[0]&0x3f != 0 {
+			b.kinds[1]++
+		} else {
+			b.kinds[2]++
+		}
+		m.insert(b, uint32(len(name)+len(value)+32))
+	default: // 0000xxxx / 0001xxxx literal without indexing
+		if out[0]&0x0f != 0 {
+			b.kinds[1]++
+		} else {
+			b.kinds[2]++
+		}
+	}
+	b.peak = max(b.peak, m.size)
+}
+
+// insert adds an entry, evicting the oldest ones to stay within capacity (RFC 7541 4.4)
+func (m *hpackMirror) insert(b *headerBlock, size uint32) {
+	m.entries = append(m.entries, size)
+	m.size += size
+	b.inserts++
+	for m.size > m.maxSize && len(m.entries) > 0 {
+		m.size -= m.entries[0]
+		m.entries = m.entries[1:]
+		b.evicts++
+	}
+}
+
+// skipTableSizeUpdate drops dynamic table size updates (001xxxxx) ahead of a field
+func skipTableSizeUpdate(out []byte) []byte {
+	for len(out) > 0 && out[0]&0xe0 == 0x20 {
+		i := 1
+		if out[0]&0x1f == 0x1f {
+			for i < len(out) && out[i]&0x80 != 0 {
+				i++
+			}
+			i++
+		}
+		out = out[min(i, len(out)):]
+	}
+	return out
+}
+
+// HeaderValues selects how header-bloat header values change between requests
+type HeaderValues string
+
+const (
+	HeaderValuesStatic  HeaderValues = "static"  // Same values every request
+	HeaderValuesVarying HeaderValues = "varying" // Every 4th header carries a per-request value
+	HeaderValuesRandom  HeaderValues = "random"  // Fresh random values every request
+)
+
+// ParseHeaderValues validates a --header-values flag value
+func ParseHeaderValues(s string) (HeaderValues, error) {
+	switch v := HeaderValues(s); v {
+	case HeaderValuesStatic, HeaderValuesVarying, HeaderValuesRandom:
+		return v, nil
+	}
+	return "", fmt.Errorf("unknown header values %q (static|varying|random)", s)
+}
+
+// headerValue returns the value of header i for request reqID
+func (v HeaderValues) headerValue(static string, i int, reqID int64) string {
+	switch {
+	case v == HeaderValuesRandom:
+		return randomHeaderValue(len(static))
+	case v == HeaderValuesVarying && i%4 == 0:
+		// Keep the length, replace the tail with the request ID like a trace or session ID would
+		id := fmt.Sprintf("%x", uint64(reqID)*0x9e3779b97f4a7c15^uint64(i))
+		if len(id) >= len(static) {
+			return id[:len(static)]
+		}
+		return static[:len(static)-len(id)] + id
+	}
+	return static
+}
+
+const headerAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// randomHeaderValue returns size random token characters, which Huffman
+// coding can shrink by at most ~25% and no table can reuse
+func randomHeaderValue(size int) string {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = headerAlphabet[rand.IntN(len(headerAlphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/quic-go/qpack"
+)
+
+func TestQPACKKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		field qpack.HeaderField
+		want  int
+	}{
+		{"static indexed", qpack.HeaderField{Name: ":method", Value: "GET"}, 0},
+		{"static name ref", qpack.HeaderField{Name: ":authority", Value: "example.com"}, 1},
+		{"literal name", qpack.HeaderField{Name: "x-bloat-1", Value: "abc"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := qpack.NewEncoder(&buf)
+			if err := enc.WriteField(tt.field); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.Bytes()[2:] // Field section prefix
+			if got := qpackKind(out); got != tt.want {
+				t.Errorf("qpackKind(%x) = %d, want %d", out, got, tt.want)
+			}
+		})
+	}
+	if got := qpackKind(nil); got != 2 {
+		t.Errorf("qpackKind(nil) = %d, want 2", got)
+	}
+}
+
+func TestSkipTableSizeUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{"no update", []byte{0x82}, []byte{0x82}},
+		{"short update", []byte{0x20, 0x82}, []byte{0x82}},
+		{"multi-byte update", []byte{0x3f, 0xe1, 0x1f, 0x82}, []byte{0x82}},
+		{"two updates", []byte{0x20, 0x3f, 0xe1, 0x1f, 0x40}, []byte{0x40}},
+		{"update only", []byte{0x3f, 0xe1, 0x1f}, []byte{}},
+		{"truncated update", []byte{0x3f, 0xe1}, []byte{}},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skipTableSizeUpdate(tt.in); !bytes.Equal(got, tt.want) {
+				t.Errorf("skipTableSizeUpdate(%x) = %x, want %x", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHPACKMirror(t *testing.T) {
+	fields := [][2]string{
+		{":method", "GET"},            // Static table entry
+		{":authority", "example.com"}, // Static name, inserted
+		{"x-bloat-1", "some-value"},   // New name, inserted
+	}
+	m := newHPACKMirror(4096)
+	encode := func() *headerBlock {
+		b := &headerBlock{}
+		for _, f := range fields {
+			m.encode(b, f[0], f[1])
+		}
+		return b
+	}
+
+	first := encode()
+	if want := [3]uint64{1, 1, 1}; first.kinds != want {
+		t.Errorf("first block kinds = %v, want %v", first.kinds, want)
+	}
+	if first.inserts != 2 || first.evicts != 0 {
+		t.Errorf("first block inserts=%d evicts=%d, want 2, 0", first.inserts, first.evicts)
+	}
+	wantPeak := uint32(len(":authority") + len("example.com") + 32 + len("x-bloat-1") + len("some-value") + 32)
+	if first.peak != wantPeak {
+		t.Errorf("table peak = %d, want %d", first.peak, wantPeak)
+	}
+
+	// The dynamic table now holds every field: the repeat is fully indexed
+	second := encode()
+	if want := [3]uint64{3, 0, 0}; second.kinds != want {
+		t.Errorf("second block kinds = %v, want %v", second.kinds, want)
+	}
+	if second.inserts != 0 || second.encoded != 3 || second.encoded >= first.encoded {
+		t.Errorf("second block inserts=%d encoded=%dB (first %dB), want 0 inserts and 3 one-byte indexes", second.inserts, second.encoded, first.encoded)
+	}
+}
+
+func TestHPACKMirrorEviction(t *testing.T) {
+	// Each entry takes 32+4 bytes: a 64-byte table holds one at a time
+	m := newHPACKMirror(64)
+	b := &headerBlock{}
+	m.encode(b, "x-a", "1")
+	m.encode(b, "x-b", "2")
+	m.encode(b, "x-c", "3")
+	if b.inserts != 3 || b.evicts != 2 {
+		t.Errorf("inserts=%d evicts=%d, want 3, 2", b.inserts, b.evicts)
+	}
+	if b.peak != 36 || m.size != 36 {
+		t.Errorf("peak=%d size=%d, want 36, 36", b.peak, m.size)
+	}
+
+	// A table grown past the 4096-byte default keeps more entries
+	big := newHPACKMirror(65536)
+	b = &headerBlock{}
+	for i := 0; i < 200; i++ {
+		big.encode(b, "x-bloat", randomHeaderValue(32))
+	}
+	if b.evicts != 0 || b.peak <= 4096 {
+		t.Errorf("65536-byte table: evicts=%d peak=%d, want no evictions past 4096 bytes", b.evicts, b.peak)
+	}
+}
+
+func TestHeaderValues(t *testing.T) {
+	for _, s := range []string{"static", "varying", "random"} {
+		if _, err := ParseHeaderValues(s); err != nil {
+			t.Errorf("ParseHeaderValues(%q) = %v", s, err)
+		}
+	}
+	if _, err := ParseHeaderValues("sometimes"); err == nil {
+		t.Error("ParseHeaderValues(sometimes) should fail")
+	}
+
+	const static = "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name     string
+		v        HeaderValues
+		i        int
+		wantSame bool // Same value for two request IDs
+		wantEq   bool // Equal to the static value
+	}{
+		{"static", HeaderValuesStatic, 0, true, true},
+		{"varying, stable header", HeaderValuesVarying, 1, true, true},
+		{"varying, per-request header", HeaderValuesVarying, 4, false, false},
+		{"random", HeaderValuesRandom, 1, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := tt.v.headerValue(static, tt.i, 1), tt.v.headerValue(static, tt.i, 2)
+			if len(a) != len(static) || len(b) != len(static) {
+				t.Errorf("lengths %d, %d, want %d", len(a), len(b), len(static))
+			}
+			if (a == b) != tt.wantSame {
+				t.Errorf("values for two requests %q, %q: same = %v, want %v", a, b, a == b, tt.wantSame)
+			}
+			if (a == static) != tt.wantEq {
+				t.Errorf("value %q equal to static = %v, want %v", a, a == static, tt.wantEq)
+			}
+		})
+	}
+	if got := randomHeaderValue(64); slices.ContainsFunc([]byte(got), func(c byte) bool { return !bytes.ContainsRune([]byte(headerAlphabet), rune(c)) }) {
+		t.Errorf("randomHeaderValue = %q, want token characters only", got)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 )
@@ -16,3 +17,20 @@ func AbsOrEmpty(p, cwd string) string {
 	}
 	return filepath.Join(cwd, p)
 }
+
+// MergeFields copies extra config fields into dst and returns dst
+func MergeFields(dst map[string]interface{}, extra map[string]interface{}) map[string]interface{} {
+	for k, v := range extra {
+		dst[k] = v
+	}
+	return dst
+}
+
+// Meta converts config fields into string metadata for Summary.Meta
+func Meta(fields map[string]interface{}) map[string]string {
+	m := make(map[string]string, len(fields))
+	for k, v := range fields {
+		m[k] = fmt.Sprint(v)
+	}
+	return m
+}
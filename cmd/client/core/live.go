@@ -0,0 +1,174 @@
+package core
+
+import (
+	"context"
+	"flag"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Live configures the progress output printed while a run is in flight
+type Live struct {
+	TUI    bool          // Full-screen terminal dashboard instead of progress log lines
+	Window time.Duration // Rolling window for live latency percentiles
+}
+
+// RegisterLiveFlags registers live progress flags on fs
+func RegisterLiveFlags(fs *flag.FlagSet) *Live {
+	l := &Live{}
+	fs.BoolVar(&l.TUI, "tui", false, "full-screen terminal dashboard during the run (falls back to progress logs without a terminal)")
+	fs.DurationVar(&l.Window, "live-window", 5*time.Second, "rolling window for live p50/p99")
+	return l
+}
+
+// LiveStats is one snapshot of a running benchmark
+type LiveStats struct {
+	Elapsed   time.Duration
+	OK        uint64
+	Err       uint64
+	DeltaOK   uint64 // Completed in the last second
+	DeltaErr  uint64
+	Timeouts  uint64
+	TargetRPS int64 // 0 when the scenario has no rate target
+	InFlight  int64
+	OpenConns int64
+	P50ms     float64 // Over the rolling window
+	P99ms     float64
+	Window    time.Duration
+	Phase     string
+}
+
+// RPS is the completion rate of the last second
+func (s LiveStats) RPS() uint64 {
+	return s.DeltaOK + s.DeltaErr
+}
+
+// ErrRatePct is the error rate of the last second
+func (s LiveStats) ErrRatePct() float64 {
+	if s.RPS() == 0 {
+		return 0
+	}
+	return 100 * float64(s.DeltaErr) / float64(s.RPS())
+}
+
+// liveLatency buffers latencies completed since the last progress tick.
+// Nothing is buffered until a consumer enables it, so quiet runs do not keep
+// a second copy of every latency
+type liveLatency struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	cur     []float64
+}
+
+// enable starts buffering; the returned func stops it and drops the buffer
+func (l *liveLatency) enable() func() {
+	l.enabled.Store(true)
+	return func() {
+		l.enabled.Store(false)
+		l.drain()
+	}
+}
+
+func (l *liveLatency) observe(ms float64) {
+	if !l.enabled.Load() {
+		return
+	}
+	l.mu.Lock()
+	l.cur = append(l.cur, ms)
+	l.mu.Unlock()
+}
+
+func (l *liveLatency) drain() []float64 {
+	l.mu.Lock()
+	b := l.cur
+	l.cur = nil
+	l.mu.Unlock()
+	return b
+}
+
+// SetTargetRPS publishes the rate the dispatcher is currently aiming for
+func (c *Counters) SetTargetRPS(rps int) {
+	c.targetRPS.Store(int64(rps))
+}
+
+// ProgressPrinter prints progress every second, as log lines or as a
+// terminal dashboard when live.TUI is set
+func ProgressPrinter(ctx context.Context, counters *Counters, logger *Logger, live *Live) {
+	if live == nil {
+		live = &Live{}
+	}
+	window := live.Window
+	if window < time.Second {
+		window = time.Second
+	}
+	nBuckets := int(window / time.Second)
+	defer counters.live.enable()()
+
+	var ui *tui
+	if live.TUI {
+		if isTerminal(os.Stdout) {
+			ui = startTUI(os.Stdout)
+			defer ui.stop()
+		} else {
+			logger.Info("--tui needs a terminal on stdout, using progress logs")
+		}
+	}
+
+	tk := time.NewTicker(1 * time.Second)
+	defer tk.Stop()
+	start := time.Now()
+	var lastOK, lastErr uint64
+	var buckets [][]float64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+			buckets = append(buckets, counters.live.drain())
+			if len(buckets) > nBuckets {
+				buckets = buckets[1:]
+			}
+			var lat []float64
+			for _, b := range buckets {
+				lat = append(lat, b...)
+			}
+			sort.Float64s(lat)
+
+			o := counters.TotalOK.Load()
+			e := counters.TotalErr.Load()
+			s := LiveStats{
+				Elapsed:   time.Since(start),
+				OK:        o,
+				Err:       e,
+				DeltaOK:   o - lastOK,
+				DeltaErr:  e - lastErr,
+				Timeouts:  counters.TotalTimeout.Load(),
+				TargetRPS: counters.targetRPS.Load(),
+				InFlight:  counters.InFlight.Load(),
+				OpenConns: OpenConnections(),
+				P50ms:     Percentile(lat, 0.50),
+				P99ms:     Percentile(lat, 0.99),
+				Window:    window,
+			}
+			if tag := counters.phase.Load(); tag != nil {
+				s.Phase = tag.name
+			}
+			lastOK, lastErr = o, e
+
+			if ui != nil {
+				ui.render(s)
+			} else {
+				logger.Progress(s)
+			}
+		}
+	}
+}
+
+// isTerminal reports whether f is a character device
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
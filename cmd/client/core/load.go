@@ -0,0 +1,63 @@
+package core
+
+import (
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Upper bounds for load flags. Above MaxLoadRPS the dispatch interval
+// (time.Second / rps) drops below a microsecond and reaches zero at 1e9
+const (
+	MaxLoadWorkers = 100_000
+	MaxLoadPayload = 1 << 30
+	MaxLoadRPS     = 1_000_000
+)
+
+// Load holds the load shape of a scenario. Defaults are the scenario's
+// fixed configuration; the flags let sweeps vary one value at a time
+type Load struct {
+	Workers  int           // Concurrent clients, workers or devices
+	Payload  int           // Request payload in bytes
+	RPS      int           // Target (or peak) request rate
+	Duration time.Duration // Test duration
+
+	def *Load // Fixed configuration; zero fields have no flag
+}
+
+// RegisterLoadFlags registers an override flag for each non-zero field of def
+func RegisterLoadFlags(fs *flag.FlagSet, def Load) *Load {
+	l := &Load{def: &def}
+	if def.Workers != 0 {
+		fs.IntVar(&l.Workers, "workers", def.Workers, "concurrent clients/workers (default: fixed configuration)")
+	}
+	if def.Payload != 0 {
+		fs.IntVar(&l.Payload, "payload", def.Payload, "request payload in bytes (default: fixed configuration)")
+	}
+	if def.RPS != 0 {
+		fs.IntVar(&l.RPS, "rps", def.RPS, "target request rate (default: fixed configuration)")
+	}
+	if def.Duration != 0 {
+		fs.DurationVar(&l.Duration, "duration", def.Duration, "test duration (default: fixed configuration)")
+	}
+	return l
+}
+
+// Validate rejects values the scenarios cannot run with. Call after parsing;
+// only fields with a flag are checked
+func (l *Load) Validate() error {
+	if l.def == nil {
+		return nil
+	}
+	switch {
+	case l.def.Workers != 0 && (l.Workers <= 0 || l.Workers > MaxLoadWorkers):
+		return fmt.Errorf("--workers must be in 1..%d, got %d", MaxLoadWorkers, l.Workers)
+	case l.def.Payload != 0 && (l.Payload <= 0 || l.Payload > MaxLoadPayload):
+		return fmt.Errorf("--payload must be in 1..%d bytes, got %d", MaxLoadPayload, l.Payload)
+	case l.def.RPS != 0 && (l.RPS <= 0 || l.RPS > MaxLoadRPS):
+		return fmt.Errorf("--rps must be in 1..%d, got %d", MaxLoadRPS, l.RPS)
+	case l.def.Duration != 0 && l.Duration <= 0:
+		return fmt.Errorf("--duration must be > 0, got %v", l.Duration)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -110,21 +111,113 @@ func (l *Logger) Error(reqID int64, err error) {
 }
 
 // ErrorThrottled logs errors with throttling (first N + every Mth)
-func (l *Logger) ErrorThrottled(errCount int64, err error, firstN int64, everyM int64) {
+func (l *Logger) ErrorThrottled(errCount int64, errClass string, err error, firstN int64, everyM int64) {
 	if l.level < LogLevelMinimal {
 		return
 	}
 	if errCount <= firstN || errCount%everyM == 0 {
-		log.Printf("[ERROR #%d] %v", errCount, err)
+		log.Printf("[ERROR #%d] class=%s %v", errCount, errClass, err)
+	}
+}
+
+// Window logs which samples the headline stats are based on
+func (l *Logger) Window(s Summary) {
+	if l.level < LogLevelMinimal || s.Window == "" {
+		return
+	}
+	log.Printf("window | %s, %d warm-up samples excluded", s.Window, s.ExcludedSamples)
+	if s.WindowFallback {
+		log.Printf("window | WARNING: --warmup/--steady-state left no samples, stats include the warm-up")
+	}
+}
+
+// Phases logs the per-phase breakdown
+func (l *Logger) Phases(s Summary) {
+	if l.level < LogLevelMinimal {
+		return
+	}
+	for _, p := range s.Phases {
+		log.Printf("phase | %s cycles=%d samples=%d ok_rate=%.2f%% p50=%.3fms p90=%.3fms p99=%.3fms first_req p50=%.3fms p99=%.3fms (n=%d)",
+			p.Phase, p.Cycles, p.Samples, p.OKRatePct, p.P50ms, p.P90ms, p.P99ms, p.FirstP50ms, p.FirstP99ms, p.FirstSamples)
+	}
+}
+
+// Classes logs the per-request-class breakdown
+func (l *Logger) Classes(s Summary) {
+	if l.level < LogLevelMinimal {
+		return
+	}
+	for _, c := range s.Classes {
+		log.Printf("class | %s samples=%d ok_rate=%.2f%% rps=%.2f p50=%.3fms p90=%.3fms p99=%.3fms max=%.3fms",
+			c.Class, c.Samples, c.OKRatePct, c.RPS, c.P50ms, c.P90ms, c.P99ms, c.Maxms)
+	}
+}
+
+// Resilience logs first-attempt vs final latency and retry/hedge outcome
+func (l *Logger) Resilience(s Summary) {
+	if l.level < LogLevelMinimal || !s.Resilience.Active() {
+		return
+	}
+	r := s.Resilience
+	log.Printf("resilience | first p50=%.3fms p99=%.3fms -> final p50=%.3fms p99=%.3fms attempts=%.3f retried=%.2f%% hedged=%.2f%% hedge_wins=%.2f%%",
+		r.FirstP50ms, r.FirstP99ms, s.P50ms, s.P99ms, r.AttemptsMean, r.RetriedPct, r.HedgedPct, r.HedgeWinRatePct)
+}
+
+// ResourceUsage logs client resource usage and flags client-bound runs
+func (l *Logger) ResourceUsage(s Summary) {
+	r := s.Resources
+	if l.level < LogLevelMinimal || len(r.Samples) == 0 {
+		return
+	}
+	log.Printf("resources | cpu=%.2fs mean=%.0f%% p90=%.0f%% max=%.0f%% (%d cores) rss_peak=%s heap_peak=%s goroutines_max=%d gc=%d pause=%.3fms udp_rcvbuf_errors_host=%d",
+		r.CPUSeconds, r.CPUMeanPct, r.CPUP90Pct, r.CPUMaxPct, r.Cores, FormatBytes(int(r.PeakRSSBytes)), FormatBytes(int(r.PeakHeapBytes)),
+		r.MaxGoroutines, r.GCCount, r.GCPauseTotalms, r.UDPRcvbufErrors)
+	for _, reason := range r.Reasons {
+		log.Printf("resources | WARNING client-bound: %s", reason)
+	}
+}
+
+// ServerCost logs the server-side cost per request
+func (l *Logger) ServerCost(s Summary) {
+	if l.level < LogLevelMinimal || s.Server.Requests == 0 {
+		return
+	}
+	log.Printf("server | %s", s.Server)
+}
+
+// HeaderCompression logs request header compression
+func (l *Logger) HeaderCompression(s Summary) {
+	if l.level < LogLevelMinimal || !s.Headers.Active() {
+		return
+	}
+	log.Printf("headers | %s", s.Headers)
+}
+
+// ErrorClasses logs failed request counts per error class
+func (l *Logger) ErrorClasses(classes map[string]int) {
+	if l.level < LogLevelMinimal || len(classes) == 0 {
+		return
+	}
+	for _, k := range SortedErrorClasses(classes) {
+		log.Printf("errors | class=%s count=%d", k, classes[k])
 	}
 }
 
 // Progress logs progress information
-func (l *Logger) Progress(okCount, errCount uint64, deltaOK, deltaErr uint64) {
+func (l *Logger) Progress(s LiveStats) {
 	if l.level < LogLevelNormal {
 		return
 	}
-	log.Printf("[PROGRESS] ok=%d (+%d) err=%d (+%d)", okCount, deltaOK, errCount, deltaErr)
+	target := "-"
+	if s.TargetRPS > 0 {
+		target = strconv.FormatInt(s.TargetRPS, 10)
+	}
+	phase := ""
+	if s.Phase != "" {
+		phase = " phase=" + s.Phase
+	}
+	log.Printf("[PROGRESS] ok=%d (+%d) err=%d (+%d) timeout=%d rps=%d/%s inflight=%d conns=%d p50=%.2fms p99=%.2fms (%v)%s",
+		s.OK, s.DeltaOK, s.Err, s.DeltaErr, s.Timeouts, s.RPS(), target, s.InFlight, s.OpenConns, s.P50ms, s.P99ms, s.Window, phase)
 }
 
 // Summary logs final summary
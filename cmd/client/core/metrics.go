@@ -0,0 +1,200 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the request duration histogram, in seconds
+var latencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// latencyHistogram is a lock-free cumulative-on-read latency histogram
+type latencyHistogram struct {
+	counts [14]atomic.Uint64 // One per bucket plus +Inf
+	sumNS  atomic.Int64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	s := d.Seconds()
+	i := sort.SearchFloat64s(latencyBuckets, s)
+	h.counts[i].Add(1)
+	h.sumNS.Add(d.Nanoseconds())
+}
+
+// Observe records the outcome of one request in the live counters
+func (c *Counters) Observe(lat time.Duration, ok bool, errClass string, timedOut bool) {
+	if ok {
+		c.TotalOK.Add(1)
+	} else {
+		c.TotalErr.Add(1)
+		if timedOut {
+			c.TotalTimeout.Add(1)
+		}
+		n, _ := c.errClasses.LoadOrStore(errClass, new(atomic.Uint64))
+		n.(*atomic.Uint64).Add(1)
+	}
+	c.live.observe(float64(lat.Nanoseconds()) / 1e6)
+	c.hist.observe(lat)
+}
+
+// Metrics configures the optional live metrics endpoint of a client
+type Metrics struct {
+	Addr string // Listen address for /metrics, empty = disabled
+}
+
+// MetricLabels are constant labels attached to every exported series
+type MetricLabels struct {
+	Scenario string
+	Protocol string
+	RunID    string
+}
+
+// RegisterMetricsFlags registers the metrics endpoint flag on fs
+func RegisterMetricsFlags(fs *flag.FlagSet) *Metrics {
+	m := &Metrics{}
+	fs.StringVar(&m.Addr, "metrics-addr", "", "serve live Prometheus/OpenMetrics metrics on this address (e.g. :9464), empty = off")
+	return m
+}
+
+// Fields returns the metrics settings for startup logs and results
+func (m *Metrics) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"metrics_addr": m.Addr,
+	}
+}
+
+// Serve exposes counters on /metrics until ctx is done. The listener is
+// opened synchronously so a bad address fails the run before it starts
+func (m *Metrics) Serve(ctx context.Context, counters *Counters, labels MetricLabels, logger *Logger) error {
+	if m.Addr == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", m.Addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		om := strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text")
+		if om {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		}
+		bw := bufio.NewWriter(w)
+		writeMetrics(bw, counters, labels, om)
+		bw.Flush()
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Info("metrics endpoint stopped: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	logger.Info("Metrics endpoint: http://%s/metrics", ln.Addr())
+	return nil
+}
+
+// metricWriter renders the text exposition format, or OpenMetrics when om is set
+type metricWriter struct {
+	w      *bufio.Writer
+	om     bool
+	labels string
+}
+
+// family writes the HELP/TYPE header. OpenMetrics names counter families
+// without the _total suffix carried by their samples
+func (mw *metricWriter) family(name, typ, help string) {
+	if mw.om && typ == "counter" {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	fmt.Fprintf(mw.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (mw *metricWriter) sample(name, extra string, v float64) {
+	ls := mw.labels
+	if extra != "" {
+		ls += "," + extra
+	}
+	fmt.Fprintf(mw.w, "%s{%s} %s\n", name, ls, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func label(k, v string) string {
+	return k + `="` + labelEscaper.Replace(v) + `"`
+}
+
+// labelEscaper escapes a label value for the text exposition formats, which
+// only define \\, \" and \n; strconv.Quote would also emit \t, \x.. and \u..
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeMetrics(w *bufio.Writer, c *Counters, l MetricLabels, om bool) {
+	mw := &metricWriter{w: w, om: om, labels: strings.Join([]string{
+		label("scenario", l.Scenario), label("protocol", l.Protocol), label("run_id", l.RunID),
+	}, ",")}
+
+	mw.family("bench_requests_total", "counter", "Completed requests by result")
+	mw.sample("bench_requests_total", label("result", "ok"), float64(c.TotalOK.Load()))
+	mw.sample("bench_requests_total", label("result", "error"), float64(c.TotalErr.Load()))
+
+	mw.family("bench_request_timeouts_total", "counter", "Requests failed by timeout")
+	mw.sample("bench_request_timeouts_total", "", float64(c.TotalTimeout.Load()))
+
+	mw.family("bench_request_errors_total", "counter", "Failed requests by error class")
+	var classes []string
+	c.errClasses.Range(func(k, _ any) bool {
+		classes = append(classes, k.(string))
+		return true
+	})
+	sort.Strings(classes)
+	for _, k := range classes {
+		n, _ := c.errClasses.Load(k)
+		mw.sample("bench_request_errors_total", label("class", k), float64(n.(*atomic.Uint64).Load()))
+	}
+
+	mw.family("bench_request_duration_seconds", "histogram", "Request latency")
+	var cum uint64
+	for i, le := range latencyBuckets {
+		cum += c.hist.counts[i].Load()
+		mw.sample("bench_request_duration_seconds_bucket", label("le", strconv.FormatFloat(le, 'g', -1, 64)), float64(cum))
+	}
+	cum += c.hist.counts[len(latencyBuckets)].Load()
+	mw.sample("bench_request_duration_seconds_bucket", label("le", "+Inf"), float64(cum))
+	mw.sample("bench_request_duration_seconds_sum", "", time.Duration(c.hist.sumNS.Load()).Seconds())
+	mw.sample("bench_request_duration_seconds_count", "", float64(cum))
+
+	mw.family("bench_in_flight_requests", "gauge", "Requests currently in flight")
+	mw.sample("bench_in_flight_requests", "", float64(c.InFlight.Load()))
+
+	mw.family("bench_open_connections", "gauge", "Client connections currently open")
+	mw.sample("bench_open_connections", "", float64(OpenConnections()))
+
+	mw.family("bench_target_rps", "gauge", "Dispatcher rate target, 0 when unset")
+	mw.sample("bench_target_rps", "", float64(c.targetRPS.Load()))
+
+	if tag := c.phase.Load(); tag != nil {
+		mw.family("bench_phase_info", "gauge", "Current scenario phase")
+		mw.sample("bench_phase_info", label("phase", tag.name)+","+label("cycle", strconv.Itoa(tag.cycle)), 1)
+	}
+
+	if om {
+		fmt.Fprint(w, "# EOF\n")
+	}
+}
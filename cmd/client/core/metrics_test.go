@@ -0,0 +1,121 @@
+package core
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+func renderMetrics(c *Counters, l MetricLabels, om bool) string {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	writeMetrics(w, c, l, om)
+	w.Flush()
+	return b.String()
+}
+
+func TestWriteMetrics(t *testing.T) {
+	c := NewCounters()
+	c.Observe(3*time.Millisecond, true, "", false)
+	c.Observe(40*time.Millisecond, true, "", false)
+	c.Observe(2*time.Second, false, ErrClassDeadline, true)
+	c.Observe(time.Minute, false, ErrClassConnReset, false)
+	c.SetTargetRPS(250)
+	c.SetPhase("ramp", 2)
+	l := MetricLabels{Scenario: "burst-traffic", Protocol: "h3", RunID: "r1"}
+	const ls = `scenario="burst-traffic",protocol="h3",run_id="r1"`
+
+	tests := []struct {
+		name    string
+		om      bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "prometheus text",
+			want: []string{
+				"# HELP bench_requests_total Completed requests by result",
+				"# TYPE bench_requests_total counter",
+				"bench_requests_total{" + ls + `,result="ok"} 2`,
+				"bench_requests_total{" + ls + `,result="error"} 2`,
+				"bench_request_timeouts_total{" + ls + "} 1",
+				"bench_request_errors_total{" + ls + `,class="conn_reset"} 1`,
+				"bench_request_errors_total{" + ls + `,class="deadline_exceeded"} 1`,
+				"# TYPE bench_request_duration_seconds histogram",
+				"bench_request_duration_seconds_bucket{" + ls + `,le="0.001"} 0`,
+				"bench_request_duration_seconds_bucket{" + ls + `,le="0.005"} 1`,
+				"bench_request_duration_seconds_bucket{" + ls + `,le="0.05"} 2`,
+				"bench_request_duration_seconds_bucket{" + ls + `,le="2.5"} 3`,
+				"bench_request_duration_seconds_bucket{" + ls + `,le="10"} 3`,
+				"bench_request_duration_seconds_bucket{" + ls + `,le="+Inf"} 4`,
+				"bench_request_duration_seconds_sum{" + ls + "} 62.043",
+				"bench_request_duration_seconds_count{" + ls + "} 4",
+				"bench_target_rps{" + ls + "} 250",
+				"bench_phase_info{" + ls + `,phase="ramp",cycle="2"} 1`,
+			},
+			notWant: []string{"# EOF"},
+		},
+		{
+			name: "openmetrics",
+			om:   true,
+			want: []string{
+				"# TYPE bench_requests counter",
+				"bench_requests_total{" + ls + `,result="ok"} 2`,
+				"# TYPE bench_request_timeouts counter",
+				"# TYPE bench_in_flight_requests gauge",
+				"# EOF",
+			},
+			notWant: []string{"# TYPE bench_requests_total counter"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderMetrics(c, l, tt.om)
+			lines := map[string]bool{}
+			for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+				lines[line] = true
+			}
+			for _, w := range tt.want {
+				if !lines[w] {
+					t.Errorf("missing line %q in:\n%s", w, out)
+				}
+			}
+			for _, w := range tt.notWant {
+				if lines[w] {
+					t.Errorf("unexpected line %q", w)
+				}
+			}
+			if tt.om && !strings.HasSuffix(out, "# EOF\n") {
+				t.Error("OpenMetrics output must end with # EOF")
+			}
+		})
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	tests := []struct {
+		v, want string
+	}{
+		{"plain", `k="plain"`},
+		{`a"b`, `k="a\"b"`},
+		{`c:\tmp`, `k="c:\\tmp"`},
+		{"two\nlines", `k="two\nlines"`},
+		// Only \\, \" and \n are escapes in the exposition formats
+		{"tab\there", "k=\"tab\there\""},
+		{"héllo ✓", `k="héllo ✓"`},
+	}
+	for _, tt := range tests {
+		if got := label("k", tt.v); got != tt.want {
+			t.Errorf("label(%q) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+
+	// Escaped labels end up in every sample line
+	l := MetricLabels{Scenario: "burst-traffic", Protocol: "h3", RunID: "run \"1\"\n\tné"}
+	out := renderMetrics(NewCounters(), l, false)
+	want := "bench_request_timeouts_total{scenario=\"burst-traffic\",protocol=\"h3\",run_id=\"run \\\"1\\\"\\n\tné\"} 0\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("missing line %q in:\n%s", want, out)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"log"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -25,12 +26,25 @@ func WriteCSV(path string, rows []Record, logger *Logger) error {
 	w := csv.NewWriter(f)
 	defer w.Flush()
 
-	_ = w.Write([]string{"ts_unix_ns", "latency_ns", "ok"})
+	_ = w.Write([]string{"ts_unix_ns", "latency_ns", "ok", "err_class", "timeout", "attempts", "hedges", "hedge_won", "first_latency_ns", "warmup", "phase", "cycle", "phase_first", "class", "trace_id", "request_id"})
 	for _, r := range rows {
 		_ = w.Write([]string{
 			strconv.FormatInt(r.TsUnixNS, 10),
 			strconv.FormatInt(r.LatencyNS, 10),
 			strconv.FormatBool(r.OK),
+			r.ErrClass,
+			strconv.FormatBool(r.Timeout),
+			strconv.Itoa(r.Attempts),
+			strconv.Itoa(r.Hedges),
+			strconv.FormatBool(r.HedgeWon),
+			strconv.FormatInt(r.FirstLatencyNS, 10),
+			strconv.FormatBool(r.Warmup),
+			r.Phase,
+			strconv.Itoa(r.Cycle),
+			strconv.FormatBool(r.PhaseFirst),
+			r.Class,
+			r.TraceID,
+			r.RequestID,
 		})
 	}
 
@@ -38,31 +52,53 @@ func WriteCSV(path string, rows []Record, logger *Logger) error {
 	return nil
 }
 
+// WriteJSON writes the summary and run configuration as a JSON result file
+func WriteJSON(path string, label string, s Summary, logger *Logger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Result{Label: label, Summary: s}); err != nil {
+		return err
+	}
+
+	logger.Info("JSON written: %s", path)
+	return nil
+}
+
 // WriteHTML generates a self-contained HTML dashboard
 func WriteHTML(path string, label string, s Summary, logger *Logger) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
 	}
 
-	toJS := func(v any) template.JS {
-		b, _ := json.Marshal(v)
-		return template.JS(b)
+	type errClassRow struct {
+		Class string
+		Count int
+	}
+	var errRows []errClassRow
+	for _, k := range SortedErrorClasses(s.ErrorClasses) {
+		errRows = append(errRows, errClassRow{Class: k, Count: s.ErrorClasses[k]})
 	}
 
 	data := struct {
-		Title    string
-		S        Summary
-		CDF_X_ms template.JS
-		CDF_Y    template.JS
-		THR_Ts   template.JS
-		THR_Val  template.JS
+		Title        string
+		S            Summary
+		ErrorClasses []errClassRow
+		Charts       map[string]template.HTML
 	}{
-		Title:    label,
-		S:        s,
-		CDF_X_ms: toJS(s.CDF_X_ms),
-		CDF_Y:    toJS(s.CDF_Y),
-		THR_Ts:   toJS(s.THR_Ts),
-		THR_Val:  toJS(s.THR_Val),
+		Title:        label,
+		S:            s,
+		ErrorClasses: errRows,
+		Charts:       summaryCharts(s),
 	}
 
 	t, err := template.New("page").Parse(htmlTemplate)
@@ -84,6 +120,101 @@ func WriteHTML(path string, label string, s Summary, logger *Logger) error {
 	return nil
 }
 
+// summaryCharts renders the dashboard charts of a summary, keyed by id
+func summaryCharts(s Summary) map[string]template.HTML {
+	charts := map[string]template.HTML{}
+	xy := func(n int, f func(i int) (float64, float64)) []ChartPoint {
+		pts := make([]ChartPoint, n)
+		for i := range pts {
+			pts[i].X, pts[i].Y = f(i)
+		}
+		return pts
+	}
+	cdf := func(xs, ys []float64) []ChartPoint {
+		return xy(len(xs), func(i int) (float64, float64) { return xs[i], ys[i] })
+	}
+	thr := func(ts []int64, vs []int) []ChartPoint {
+		return xy(len(ts), func(i int) (float64, float64) { return float64(ts[i]), float64(vs[i]) })
+	}
+
+	charts["cdf"] = Chart{
+		XTitle: "Latency (ms)", YTitle: "CDF", YMin: Float(0), YMax: Float(1),
+		Lines: []ChartLine{{Label: "CDF", Points: cdf(s.CDF_X_ms, s.CDF_Y)}},
+	}.SVG()
+	charts["thr"] = Chart{
+		XTitle: "Time", YTitle: "Requests/sec", XTime: true, YMin: Float(0),
+		Lines: []ChartLine{{Label: "RPS", Points: thr(s.THR_Ts, s.THR_Val)}},
+	}.SVG()
+
+	if len(s.Series) > 0 {
+		var used []SeriesPoint
+		for _, p := range s.Series {
+			if p.Samples > 0 {
+				used = append(used, p)
+			}
+		}
+		at := func(f func(p SeriesPoint) float64) []ChartPoint {
+			return xy(len(used), func(i int) (float64, float64) { return float64(used[i].TsUnixNS) / 1e9, f(used[i]) })
+		}
+		charts["lat-time"] = Chart{
+			XTitle: "Time", YTitle: "Latency (ms)", XTime: true, YMin: Float(0),
+			Lines: []ChartLine{
+				{Label: "p50", Points: at(func(p SeriesPoint) float64 { return p.P50ms })},
+				{Label: "p90", Points: at(func(p SeriesPoint) float64 { return p.P90ms })},
+				{Label: "p99", Points: at(func(p SeriesPoint) float64 { return p.P99ms })},
+			},
+		}.SVG()
+		charts["err-time"] = Chart{
+			XTitle: "Time", YTitle: "Errors (%)", XTime: true, YMin: Float(0),
+			Lines: []ChartLine{{Label: "error rate %", Points: at(func(p SeriesPoint) float64 { return p.ErrRatePct })}},
+		}.SVG()
+	}
+
+	if rs := s.Resources.Samples; len(rs) > 0 {
+		pts := func(f func(p ResourceSample) float64) []ChartPoint {
+			return xy(len(rs), func(i int) (float64, float64) { return float64(rs[i].TsUnixNS) / 1e9, f(rs[i]) })
+		}
+		charts["res-cpu"] = Chart{
+			XTitle: "Time", YTitle: "CPU %", XTime: true, YMin: Float(0),
+			Lines: []ChartLine{{Label: "CPU % (100 = one core)", Points: pts(func(p ResourceSample) float64 { return p.CPUPct })}},
+		}.SVG()
+		charts["res-mem"] = Chart{
+			XTitle: "Time", YTitle: "MiB", Y2Title: "Goroutines", XTime: true, YMin: Float(0),
+			Lines: []ChartLine{
+				{Label: "RSS (MiB)", Points: pts(func(p ResourceSample) float64 { return float64(p.RSSBytes) / 1048576 })},
+				{Label: "Heap (MiB)", Points: pts(func(p ResourceSample) float64 { return float64(p.HeapBytes) / 1048576 })},
+				{Label: "Goroutines", Points: pts(func(p ResourceSample) float64 { return float64(p.Goroutines) }), Right: true},
+			},
+		}.SVG()
+	}
+
+	if s.Capacity.Active() {
+		curve := s.Capacity.Curve()
+		pts := func(f func(p CapacityProbe) float64) []ChartPoint {
+			return xy(len(curve), func(i int) (float64, float64) { return float64(curve[i].TargetRPS), f(curve[i]) })
+		}
+		charts["capacity"] = Chart{
+			XTitle: "Offered RPS", YTitle: "Latency (ms)", YMin: Float(0),
+			Lines: []ChartLine{
+				{Label: "p50 (ms)", Points: pts(func(p CapacityProbe) float64 { return p.P50ms }), Markers: true},
+				{Label: "p99 (ms)", Points: pts(func(p CapacityProbe) float64 { return p.P99ms }), Markers: true},
+				{Label: "SLO p99 (ms)", Points: pts(func(CapacityProbe) float64 { return s.Capacity.SLOP99ms }), Dashed: true},
+			},
+		}.SVG()
+	}
+
+	if len(s.Classes) > 0 {
+		var cdfs, thrs []ChartLine
+		for _, c := range s.Classes {
+			cdfs = append(cdfs, ChartLine{Label: c.Class, Points: cdf(c.CDF_X_ms, c.CDF_Y)})
+			thrs = append(thrs, ChartLine{Label: c.Class, Points: thr(c.THR_Ts, c.THR_Val)})
+		}
+		charts["cdf-class"] = Chart{XTitle: "Latency (ms)", YTitle: "CDF", YMin: Float(0), YMax: Float(1), Lines: cdfs}.SVG()
+		charts["thr-class"] = Chart{XTitle: "Time", YTitle: "Requests/sec", XTime: true, YMin: Float(0), Lines: thrs}.SVG()
+	}
+	return charts
+}
+
 const htmlTemplate = `<!doctype html>
 <html lang="en">
 <head>
@@ -97,21 +228,28 @@ h1 { margin-bottom: 0; }
 table { border-collapse: collapse; margin-top: 16px; }
 td, th { border: 1px solid #ddd; padding: 6px 10px; text-align: left; }
 .grid { display: grid; grid-template-columns: 1fr; gap: 16px; margin-top: 18px; }
-.chart { width: 100%; height: 360px; }
+.chart { width: 100%; height: auto; }
+.pass { color: #2e7d32; }
+.fail { color: #c62828; font-weight: bold; }
+.warn { margin-top: 12px; padding: 8px 12px; border: 1px solid #e0a800; background: #fff8e1; border-radius: 4px; }
 .code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; background: #f6f8fa; padding: 2px 6px; border-radius: 4px; }
 @media (min-width: 900px) { .grid { grid-template-columns: 1fr 1fr; } }
 </style>
-<script src="https://cdn.jsdelivr.net/npm/chart.js@4"></script>
 </head>
 <body>
 <h1>{{ .Title }}</h1>
 <div class="sub">Auto-generated benchmark dashboard</div>
-
+{{ if .S.Resources.ClientBound }}<div class="warn"><b>Client-bound run:</b> results may reflect client limits rather than the protocol.
+<ul>{{ range .S.Resources.Reasons }}<li>{{ . }}</li>{{ end }}</ul></div>
+{{ end }}{{ if .S.WindowFallback }}<div class="warn"><b>Measurement window empty:</b> --warmup/--steady-state left no samples, so the stats below include the warm-up ({{ .S.Window }}).</div>
+{{ end }}
 <h2>Summary</h2>
 <table>
 <tbody>
-	<tr><td>samples</td><td>{{ .S.Samples }}</td></tr>
+	{{ if .S.Window }}<tr><td>window</td><td>{{ .S.Window }} ({{ .S.ExcludedSamples }} samples excluded)</td></tr>
+	{{ end }}<tr><td>samples</td><td>{{ .S.Samples }}</td></tr>
 	<tr><td>ok_rate_%</td><td>{{ printf "%.3f" .S.OKRatePct }}</td></tr>
+	<tr><td>timeouts</td><td>{{ .S.Timeouts }} ({{ printf "%.3f" .S.TimeoutPct }}%)</td></tr>
 	<tr><td>rps</td><td>{{ printf "%.2f" .S.RPS }}</td></tr>
 	<tr><td>duration_s</td><td>{{ printf "%.3f" .S.DurationS }}</td></tr>
 	<tr><td>p50_ms</td><td>{{ printf "%.6f" .S.P50ms }}</td></tr>
@@ -124,52 +262,255 @@ td, th { border: 1px solid #ddd; padding: 6px 10px; text-align: left; }
 </tbody>
 </table>
 
+{{ if .S.Checks }}
+<h2>Thresholds</h2>
+<table>
+<thead><tr><th>threshold</th><th>actual</th><th>result</th></tr></thead>
+<tbody>
+{{ range .S.Checks }}	<tr><td>{{ .Name }}</td><td>{{ if .Error }}{{ .Error }}{{ else }}{{ .Actual }}{{ end }}</td><td class="{{ if .Pass }}pass{{ else }}fail{{ end }}">{{ if .Pass }}pass{{ else }}FAIL{{ end }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Classes }}
+<h2>Per-Class Breakdown</h2>
+<table>
+<thead><tr><th>class</th><th>samples</th><th>ok_rate_%</th><th>rps</th><th>p50_ms</th><th>p90_ms</th><th>p95_ms</th><th>p99_ms</th><th>mean_ms</th><th>max_ms</th></tr></thead>
+<tbody>
+{{ range .S.Classes }}	<tr><td>{{ .Class }}</td><td>{{ .Samples }}</td><td>{{ printf "%.3f" .OKRatePct }}</td><td>{{ printf "%.2f" .RPS }}</td><td>{{ printf "%.3f" .P50ms }}</td><td>{{ printf "%.3f" .P90ms }}</td><td>{{ printf "%.3f" .P95ms }}</td><td>{{ printf "%.3f" .P99ms }}</td><td>{{ printf "%.3f" .Meanms }}</td><td>{{ printf "%.3f" .Maxms }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Phases }}
+<h2>Per-Phase Breakdown</h2>
+<table>
+<thead><tr><th>phase</th><th>cycles</th><th>samples</th><th>ok_rate_%</th><th>p50_ms</th><th>p90_ms</th><th>p99_ms</th><th>mean_ms</th><th>first_req_p50_ms</th><th>first_req_p99_ms</th></tr></thead>
+<tbody>
+{{ range .S.Phases }}	<tr><td>{{ .Phase }}</td><td>{{ .Cycles }}</td><td>{{ .Samples }}</td><td>{{ printf "%.3f" .OKRatePct }}</td><td>{{ printf "%.3f" .P50ms }}</td><td>{{ printf "%.3f" .P90ms }}</td><td>{{ printf "%.3f" .P99ms }}</td><td>{{ printf "%.3f" .Meanms }}</td><td>{{ printf "%.3f" .FirstP50ms }}</td><td>{{ printf "%.3f" .FirstP99ms }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Resilience.Active }}
+<h2>Retry &amp; Hedging</h2>
+<table>
+<tbody>
+	<tr><td>first_attempt_p50_ms</td><td>{{ printf "%.6f" .S.Resilience.FirstP50ms }}</td></tr>
+	<tr><td>first_attempt_p90_ms</td><td>{{ printf "%.6f" .S.Resilience.FirstP90ms }}</td></tr>
+	<tr><td>first_attempt_p99_ms</td><td>{{ printf "%.6f" .S.Resilience.FirstP99ms }}</td></tr>
+	<tr><td>attempts_per_request</td><td>{{ printf "%.3f" .S.Resilience.AttemptsMean }}</td></tr>
+	<tr><td>retried_%</td><td>{{ printf "%.3f" .S.Resilience.RetriedPct }}</td></tr>
+	<tr><td>hedged_%</td><td>{{ printf "%.3f" .S.Resilience.HedgedPct }}</td></tr>
+	<tr><td>hedge_win_rate_%</td><td>{{ printf "%.3f" .S.Resilience.HedgeWinRatePct }}</td></tr>
+</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Resources.Samples }}
+<h2>Client Resources</h2>
+<table>
+<tbody>
+	<tr><td>cpu_seconds</td><td>{{ printf "%.3f" .S.Resources.CPUSeconds }} ({{ .S.Resources.Cores }} cores)</td></tr>
+	<tr><td>cpu_mean_%</td><td>{{ printf "%.1f" .S.Resources.CPUMeanPct }}</td></tr>
+	<tr><td>cpu_p90_%</td><td>{{ printf "%.1f" .S.Resources.CPUP90Pct }}</td></tr>
+	<tr><td>cpu_max_%</td><td>{{ printf "%.1f" .S.Resources.CPUMaxPct }}</td></tr>
+	<tr><td>rss_peak_bytes</td><td>{{ .S.Resources.PeakRSSBytes }}</td></tr>
+	<tr><td>heap_peak_bytes</td><td>{{ .S.Resources.PeakHeapBytes }}</td></tr>
+	<tr><td>goroutines_max</td><td>{{ .S.Resources.MaxGoroutines }}</td></tr>
+	<tr><td>gc_count</td><td>{{ .S.Resources.GCCount }}</td></tr>
+	<tr><td>gc_pause_total_ms</td><td>{{ printf "%.3f" .S.Resources.GCPauseTotalms }}</td></tr>
+	<tr><td>udp_in_errors (host-wide)</td><td>{{ .S.Resources.UDPInErrors }}</td></tr>
+	<tr><td>udp_rcvbuf_errors (host-wide)</td><td>{{ .S.Resources.UDPRcvbufErrors }}</td></tr>
+</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Server.Requests }}
+<h2>Server Cost ({{ .S.Server.Protocol }})</h2>
+<table>
+<tbody>
+	<tr><td>requests</td><td>{{ .S.Server.Requests }}</td></tr>
+	<tr><td>cpu_seconds</td><td>{{ printf "%.3f" .S.Server.CPUSeconds }}</td></tr>
+	<tr><td>cpu_us_per_request</td><td>{{ printf "%.2f" .S.Server.CPUusPerReq }}</td></tr>
+	<tr><td>allocs_per_request</td><td>{{ printf "%.2f" .S.Server.AllocsPerReq }}</td></tr>
+	<tr><td>alloc_bytes_per_request</td><td>{{ printf "%.0f" .S.Server.AllocBytesPerReq }}</td></tr>
+	<tr><td>ctx_switches_per_request</td><td>{{ printf "%.3f" .S.Server.CtxSwitchesPerReq }}</td></tr>
+	<tr><td>wire_bytes_in_per_request</td><td>{{ printf "%.0f" .S.Server.WireInPerReq }}</td></tr>
+	<tr><td>wire_bytes_out_per_request</td><td>{{ printf "%.0f" .S.Server.WireOutPerReq }}</td></tr>
+	<tr><td>packets_per_request</td><td>{{ printf "%.3f" .S.Server.PacketsPerReq }}</td></tr>
+	<tr><td>payload_bytes_per_request</td><td>{{ printf "%.0f" .S.Server.AppBytesPerReq }}</td></tr>
+	<tr><td>wire_overhead_%</td><td>{{ printf "%.2f" .S.Server.WireOverheadPct }}</td></tr>
+	<tr><td>connections</td><td>{{ .S.Server.Conns }}</td></tr>
+	<tr><td>gc_count</td><td>{{ .S.Server.GCCount }}</td></tr>
+</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Headers.Active }}
+<h2>Request Header Compression ({{ .S.Headers.Codec }}{{ if .S.Headers.Estimated }}, estimated{{ end }})</h2>
+{{ if .S.Headers.Estimated }}<div class="sub">Block sizes come from replaying request headers through a client-side {{ .S.Headers.Codec }} encoder, not from the wire. Response headers are not measured.</div>
+{{ end }}<table>
+<tbody>
+	<tr><td>header_blocks</td><td>{{ .S.Headers.Blocks }}</td></tr>
+	<tr><td>raw_bytes</td><td>{{ .S.Headers.RawBytes }}</td></tr>
+	<tr><td>encoded_bytes</td><td>{{ .S.Headers.EncodedBytes }}</td></tr>
+	<tr><td>compression_ratio</td><td>{{ printf "%.3f" .S.Headers.Ratio }}</td></tr>
+	<tr><td>savings_%</td><td>{{ printf "%.1f" .S.Headers.SavingsPct }}</td></tr>
+	<tr><td>block_bytes_first</td><td>{{ .S.Headers.FirstBlockBytes }}</td></tr>
+	<tr><td>block_bytes_mean</td><td>{{ printf "%.0f" .S.Headers.MeanBlockBytes }}</td></tr>
+	<tr><td>block_bytes_min</td><td>{{ .S.Headers.MinBlockBytes }}</td></tr>
+	<tr><td>block_bytes_max</td><td>{{ .S.Headers.MaxBlockBytes }}</td></tr>
+	<tr><td>fields_indexed_%</td><td>{{ printf "%.1f" .S.Headers.IndexedPct }}</td></tr>
+	<tr><td>fields_name_ref</td><td>{{ .S.Headers.NameRef }}</td></tr>
+	<tr><td>fields_literal</td><td>{{ .S.Headers.Literal }}</td></tr>
+	<tr><td>dynamic_table_inserts</td><td>{{ .S.Headers.TableInserts }}</td></tr>
+	<tr><td>dynamic_table_evictions</td><td>{{ .S.Headers.TableEvictions }}</td></tr>
+	<tr><td>dynamic_table_peak_bytes</td><td>{{ .S.Headers.TablePeakBytes }} / {{ .S.Headers.TableCapacity }}</td></tr>
+</tbody>
+</table>
+{{ end }}
+
+{{ if .S.TableSweep }}
+<h2>Header Table Sweep</h2>
+<table>
+<thead><tr><th>codec</th><th>table_bytes</th><th>samples</th><th>ok_rate_%</th><th>rps</th><th>p50_ms</th><th>p99_ms</th><th>est_header_bytes_per_req</th><th>est_ratio</th><th>indexed_%</th><th>wire_in_per_req</th><th>wire_out_per_req</th></tr></thead>
+<tbody>
+{{ range .S.TableSweep }}	<tr><td>{{ .Codec }}</td><td>{{ .TableSize }}</td><td>{{ .Samples }}</td><td>{{ printf "%.2f" .OKRatePct }}</td><td>{{ printf "%.2f" .RPS }}</td><td>{{ printf "%.3f" .P50ms }}</td><td>{{ printf "%.3f" .P99ms }}</td><td>{{ printf "%.0f" .HeaderBytesPerReq }}</td><td>{{ printf "%.3f" .HeaderRatio }}</td><td>{{ printf "%.1f" .IndexedPct }}</td><td>{{ printf "%.0f" .WireInPerReq }}</td><td>{{ printf "%.0f" .WireOutPerReq }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Capacity.Active }}
+<h2>Capacity Search</h2>
+<table>
+<tbody>
+	<tr><td>knee_rps</td><td>{{ if .S.Capacity.KneeRPS }}{{ .S.Capacity.KneeRPS }}{{ if .S.Capacity.Capped }} (search ceiling){{ end }}{{ else }}none{{ end }}</td></tr>
+	<tr><td>knee_p99_ms</td><td>{{ printf "%.3f" .S.Capacity.KneeP99ms }}</td></tr>
+	<tr><td>slo</td><td>p99 &le; {{ printf "%.1f" .S.Capacity.SLOP99ms }}ms, errors &le; {{ printf "%.2f" .S.Capacity.SLOErrorPct }}%, hold {{ printf "%.0f" .S.Capacity.HoldS }}s</td></tr>
+</tbody>
+</table>
+<div class="grid">
+	<div>{{ index .Charts "capacity" }}</div>
+</div>
+<table>
+<thead><tr><th>target_rps</th><th>achieved_rps</th><th>samples</th><th>ok_rate_%</th><th>p50_ms</th><th>p90_ms</th><th>p99_ms</th><th>verdict</th></tr></thead>
+<tbody>
+{{ range .S.Capacity.Curve }}	<tr><td>{{ .TargetRPS }}</td><td>{{ printf "%.1f" .AchievedRPS }}</td><td>{{ .Samples }}</td><td>{{ printf "%.2f" .OKRatePct }}</td><td>{{ printf "%.3f" .P50ms }}</td><td>{{ printf "%.3f" .P90ms }}</td><td>{{ printf "%.3f" .P99ms }}</td><td>{{ if .Pass }}pass{{ else }}{{ .Reason }}{{ end }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
+{{ if .S.ErrorClasses }}
+<h2>Errors by Class</h2>
+<table>
+<thead><tr><th>class</th><th>count</th></tr></thead>
+<tbody>
+{{ range .ErrorClasses }}	<tr><td>{{ .Class }}</td><td>{{ .Count }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
+{{ if .S.Meta }}
+<h2>Run Configuration</h2>
+<table>
+<tbody>
+{{ range $k, $v := .S.Meta }}	<tr><td>{{ $k }}</td><td>{{ $v }}</td></tr>
+{{ end }}</tbody>
+</table>
+{{ end }}
+
 <div class="grid">
 <div>
 	<h3>Latency CDF</h3>
-	<canvas id="cdf" class="chart"></canvas>
+	{{ index .Charts "cdf" }}
 </div>
 <div>
 	<h3>Throughput per Second</h3>
-	<canvas id="thr" class="chart"></canvas>
+	{{ index .Charts "thr" }}
+</div>
+{{ if .S.Series }}<div>
+	<h3>Latency over Time ({{ printf "%g" .S.SeriesIntervalS }}s windows)</h3>
+	{{ index .Charts "lat-time" }}
+</div>
+<div>
+	<h3>Error Rate over Time</h3>
+	{{ index .Charts "err-time" }}
 </div>
+{{ end }}{{ if .S.Resources.Samples }}<div>
+	<h3>Client CPU</h3>
+	{{ index .Charts "res-cpu" }}
 </div>
+<div>
+	<h3>Client Memory &amp; Goroutines</h3>
+	{{ index .Charts "res-mem" }}
+</div>
+{{ end }}{{ if .S.Classes }}<div>
+	<h3>Latency CDF by Class</h3>
+	{{ index .Charts "cdf-class" }}
+</div>
+<div>
+	<h3>Throughput by Class</h3>
+	{{ index .Charts "thr-class" }}
+</div>
+{{ end }}</div>
 
 <p style="margin-top:22px;color:#666">
-Source columns: <span class="code">ts_unix_ns, latency_ns, ok</span>. Latency in ns; converted to ms.
+Source columns: <span class="code">ts_unix_ns, latency_ns, ok, err_class, timeout, attempts, hedges, hedge_won, first_latency_ns, warmup, phase, cycle, phase_first, class, trace_id, request_id</span>. Latency in ns; converted to ms.
 </p>
 
-<script>
-const CDF_X = {{ .CDF_X_ms }};
-const CDF_Y = {{ .CDF_Y }};
-const THR_T = {{ .THR_Ts }};
-const THR_V = {{ .THR_Val }};
-
-new Chart(document.getElementById('cdf'), {
-type: 'line',
-data: { labels: CDF_X, datasets: [{ label: 'CDF', data: CDF_Y, pointRadius: 0, borderWidth: 1 }] },
-options: {
-	animation: false, parsing: false,
-	scales: {
-	x: { type: 'linear', title: { text: 'Latency (ms)', display: true } },
-	y: { min: 0, max: 1, title: { text: 'CDF', display: true } }
-	},
-	elements: { line: { tension: 0 } }
-}
-});
-
-new Chart(document.getElementById('thr'), {
-type: 'line',
-data: { labels: THR_T, datasets: [{ label: 'RPS', data: THR_V, pointRadius: 0, borderWidth: 1 }] },
-options: {
-	animation: false, parsing: false,
-	scales: {
-	x: { title: { text: 'Unix second', display: true } },
-	y: { title: { text: 'Requests/sec', display: true } }
-	},
-	elements: { line: { tension: 0 } }
-}
-});
-</script>
 </body>
 </html>`
+
+// Outputs are the result files a scenario writes after the run; empty paths
+// are skipped
+type Outputs struct {
+	CSV      string // Raw records, plus the latency series next to it
+	HTML     string
+	JSON     string
+	Markdown string // Appended, see WriteMarkdown
+	JUnit    string
+}
+
+// Write writes every requested output. Failures are logged, not returned,
+// so one bad path does not lose the other results
+func (o Outputs) Write(label string, rows []Record, s Summary, logger *Logger) {
+	if o.CSV != "" {
+		if err := WriteCSV(o.CSV, rows, logger); err != nil {
+			log.Printf("ERROR write csv: %v", err)
+		}
+		if err := WriteSeriesCSV(SeriesCSVPath(o.CSV), s, logger); err != nil {
+			log.Printf("ERROR write series csv: %v", err)
+		}
+	}
+	if o.HTML != "" {
+		if err := WriteHTML(o.HTML, label, s, logger); err != nil {
+			log.Printf("ERROR write html: %v", err)
+		}
+	}
+	if o.JSON != "" {
+		if err := WriteJSON(o.JSON, label, s, logger); err != nil {
+			log.Printf("ERROR write json: %v", err)
+		}
+	}
+	if o.Markdown != "" {
+		if err := WriteMarkdown(o.Markdown, label, s, logger); err != nil {
+			log.Printf("ERROR write markdown: %v", err)
+		}
+	}
+	if o.JUnit != "" {
+		if err := WriteJUnit(o.JUnit, label, s, logger); err != nil {
+			log.Printf("ERROR write junit: %v", err)
+		}
+	}
+}
+
+// ExitCode is the process exit code of a finished run: ThresholdExitCode when
+// a threshold check failed. Scenarios return it from run() and exit in main,
+// so deferred cleanup (connections, qlog files, tracing) still runs
+func ExitCode(s Summary) int {
+	if s.ChecksFailed() {
+		return ThresholdExitCode
+	}
+	return 0
+}
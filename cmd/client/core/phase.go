@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+)
+
+// phaseTag labels requests started during one phase instance.
+// The first request claiming a tag is flagged as PhaseFirst
+type phaseTag struct {
+	name    string
+	cycle   int
+	claimed atomic.Bool
+}
+
+func newPhaseTag(name string, cycle int) *phaseTag {
+	return &phaseTag{name: name, cycle: cycle}
+}
+
+// claim reports whether the caller is the first request of the phase instance
+func (t *phaseTag) claim() bool {
+	return t.claimed.CompareAndSwap(false, true)
+}
+
+// SetPhase tags requests started from now on with a run-wide phase,
+// for dispatcher-driven scenarios (ramp-up/sustained, idle/burst)
+func (c *Counters) SetPhase(name string, cycle int) {
+	c.phase.Store(newPhaseTag(name, cycle))
+}
+
+type phaseKey struct{}
+
+// WithPhase tags requests made with ctx, for scenarios where each worker
+// moves through phases on its own (pre/post migration). It takes
+// precedence over Counters.SetPhase
+func WithPhase(ctx context.Context, name string, cycle int) context.Context {
+	return context.WithValue(ctx, phaseKey{}, newPhaseTag(name, cycle))
+}
+
+// currentPhase returns the phase for a request started now
+func currentPhase(ctx context.Context, counters *Counters) *phaseTag {
+	if t, ok := ctx.Value(phaseKey{}).(*phaseTag); ok {
+		return t
+	}
+	return counters.phase.Load()
+}
+
+// PhaseSummary holds the stats of one phase across all its cycles
+type PhaseSummary struct {
+	Phase     string
+	Cycles    int     // Distinct cycles seen
+	Samples   int     // Requests started during the phase
+	OKRatePct float64 // Success rate percentage
+	P50ms     float64
+	P90ms     float64
+	P99ms     float64
+	Meanms    float64
+
+	// First request of each phase instance (e.g. first request after idle)
+	FirstSamples int
+	FirstP50ms   float64
+	FirstP99ms   float64
+}
+
+// Phase returns the breakdown of a phase by name
+func (s Summary) Phase(name string) PhaseSummary {
+	for _, p := range s.Phases {
+		if p.Phase == name {
+			return p
+		}
+	}
+	return PhaseSummary{Phase: name}
+}
+
+// summarizePhases groups tagged records by phase, in order of first appearance
+func summarizePhases(all []Record) []PhaseSummary {
+	type acc struct {
+		firstTS int64
+		cycles  map[int]struct{}
+		ok      int
+		lat     []float64
+		first   []float64
+	}
+	groups := make(map[string]*acc)
+	for _, r := range all {
+		if r.Phase == "" {
+			continue
+		}
+		g := groups[r.Phase]
+		if g == nil {
+			g = &acc{firstTS: r.TsUnixNS, cycles: make(map[int]struct{})}
+			groups[r.Phase] = g
+		}
+		if r.TsUnixNS < g.firstTS {
+			g.firstTS = r.TsUnixNS
+		}
+		g.cycles[r.Cycle] = struct{}{}
+		if r.OK {
+			g.ok++
+		}
+		ms := float64(r.LatencyNS) / 1e6
+		g.lat = append(g.lat, ms)
+		if r.PhaseFirst {
+			g.first = append(g.first, ms)
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(groups))
+	for k := range groups {
+		names = append(names, k)
+	}
+	sort.Slice(names, func(i, j int) bool { return groups[names[i]].firstTS < groups[names[j]].firstTS })
+
+	out := make([]PhaseSummary, 0, len(names))
+	for _, name := range names {
+		g := groups[name]
+		sort.Float64s(g.lat)
+		sort.Float64s(g.first)
+		var sum float64
+		for _, v := range g.lat {
+			sum += v
+		}
+		out = append(out, PhaseSummary{
+			Phase:        name,
+			Cycles:       len(g.cycles),
+			Samples:      len(g.lat),
+			OKRatePct:    100 * float64(g.ok) / float64(len(g.lat)),
+			P50ms:        Round6(Percentile(g.lat, 0.50)),
+			P90ms:        Round6(Percentile(g.lat, 0.90)),
+			P99ms:        Round6(Percentile(g.lat, 0.99)),
+			Meanms:       Round6(sum / float64(len(g.lat))),
+			FirstSamples: len(g.first),
+			FirstP50ms:   Round6(Percentile(g.first, 0.50)),
+			FirstP99ms:   Round6(Percentile(g.first, 0.99)),
+		})
+	}
+	return out
+}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"context"
+	"flag"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"h3-vs-h2-k6/echo/v1/echov1connect"
+)
+
+// RetryPolicy retries failed requests with exponential backoff and full jitter
+type RetryPolicy struct {
+	MaxRetries int           // Extra attempts after the first one (0 = disabled)
+	Backoff    time.Duration // Backoff before the first retry, doubled per retry
+	MaxBackoff time.Duration // Backoff cap
+}
+
+// HedgePolicy sends a duplicate request when the original has not completed
+// within the pN latency of recent requests
+type HedgePolicy struct {
+	Percentile float64       // Latency percentile that triggers a hedge, e.g. 0.95 (0 = disabled)
+	MinDelay   time.Duration // Hedge delay until enough samples are seen, and its lower bound
+	MaxHedges  int           // Duplicates per request
+}
+
+// Policy bundles the client resilience policies of a run
+type Policy struct {
+	Retry RetryPolicy
+	Hedge HedgePolicy
+}
+
+// RegisterPolicyFlags registers retry and hedging flags on fs
+func RegisterPolicyFlags(fs *flag.FlagSet) *Policy {
+	p := &Policy{}
+	fs.IntVar(&p.Retry.MaxRetries, "retries", 0, "max retries per request, exponential backoff with jitter (0 = disabled)")
+	fs.DurationVar(&p.Retry.Backoff, "retry-backoff", 50*time.Millisecond, "backoff before the first retry, doubled per retry")
+	fs.DurationVar(&p.Retry.MaxBackoff, "retry-max-backoff", 2*time.Second, "retry backoff cap")
+	fs.Float64Var(&p.Hedge.Percentile, "hedge-percentile", 0, "send a duplicate after this latency percentile of recent requests, e.g. 0.95 (0 = disabled)")
+	fs.DurationVar(&p.Hedge.MinDelay, "hedge-min-delay", 10*time.Millisecond, "hedge delay until enough samples are seen, and its lower bound")
+	fs.IntVar(&p.Hedge.MaxHedges, "hedge-max", 1, "max duplicate requests per request")
+	return p
+}
+
+// Fields returns the policy values for startup logs and results
+func (p *Policy) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"retries":           p.Retry.MaxRetries,
+		"retry_backoff":     p.Retry.Backoff,
+		"retry_max_backoff": p.Retry.MaxBackoff,
+		"hedge_percentile":  p.Hedge.Percentile,
+		"hedge_min_delay":   p.Hedge.MinDelay,
+		"hedge_max":         p.Hedge.MaxHedges,
+	}
+}
+
+// Wrap applies the enabled policies to fn: hedging per attempt, retries around it.
+// Each call tracks its own hedge latencies, so wrap each request class separately
+func (p *Policy) Wrap(fn RequestFunc) RequestFunc {
+	if p.Hedge.Percentile > 0 && p.Hedge.MaxHedges > 0 {
+		fn = HedgeRequest(fn, p.Hedge)
+	}
+	if p.Retry.MaxRetries > 0 {
+		fn = RetryRequest(fn, p.Retry)
+	}
+	return fn
+}
+
+// RetryRequest wraps fn with retries. Canceled runs, request deadlines and
+// non-retryable connect codes are returned immediately
+func RetryRequest(fn RequestFunc, p RetryPolicy) RequestFunc {
+	return func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		st := attemptStatsFrom(ctx)
+		bo := backoff{next: p.Backoff, max: p.MaxBackoff}
+		for attempt := 0; ; attempt++ {
+			st.attempts.Add(1)
+			t0 := time.Now()
+			n, err := fn(ctx, cl, reqID)
+			if attempt == 0 {
+				// The retry layer owns the first-attempt latency (includes hedging)
+				st.firstNS.Store(time.Since(t0).Nanoseconds())
+			}
+			if err == nil || attempt >= p.MaxRetries || ctx.Err() != nil || !retryable(ClassifyError(err)) {
+				return n, err
+			}
+
+			select {
+			case <-ctx.Done():
+				return n, err
+			case <-time.After(bo.delay()):
+			}
+		}
+	}
+}
+
+// backoff yields exponential backoff delays with full jitter
+type backoff struct {
+	next time.Duration // Upper bound of the next delay, doubled per call
+	max  time.Duration // Cap, 0 = none
+}
+
+// delay returns a delay uniformly in [0, next) and doubles next
+func (b *backoff) delay() time.Duration {
+	d := time.Duration(0)
+	if b.next > 0 {
+		d = time.Duration(rand.Int64N(int64(b.next)))
+	}
+	b.next *= 2
+	if b.max > 0 && b.next > b.max {
+		b.next = b.max
+	}
+	return d
+}
+
+// HedgeRequest wraps fn with hedging, tracking recent latencies on its own.
+// Once every copy in flight has failed it returns the last error; re-sending
+// is left to RetryRequest, which counts it as an attempt
+func HedgeRequest(fn RequestFunc, p HedgePolicy) RequestFunc {
+	return hedgeRequest(fn, p, newLatencyTracker(1024))
+}
+
+func hedgeRequest(fn RequestFunc, p HedgePolicy, tracker *latencyTracker) RequestFunc {
+	type result struct {
+		n     int
+		err   error
+		hedge bool
+	}
+	return func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		st := attemptStatsFrom(ctx)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Latencies count from the original send, the delay a hedge has to beat
+		t0 := time.Now()
+		results := make(chan result, 1+p.MaxHedges)
+		launch := func(hedge bool) {
+			n, err := fn(ctx, cl, reqID)
+			if !hedge {
+				// Lower bound when a hedge won and canceled the original
+				st.firstNS.CompareAndSwap(0, time.Since(t0).Nanoseconds())
+			}
+			if err == nil {
+				tracker.add(time.Since(t0))
+			}
+			results <- result{n: n, err: err, hedge: hedge}
+		}
+
+		go launch(false)
+		inflight := 1
+		hedges := 0
+		delay := tracker.quantile(p.Percentile, p.MinDelay)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case r := <-results:
+				inflight--
+				if r.err == nil {
+					if r.hedge {
+						st.hedgeWon.Store(true)
+					}
+					return r.n, nil
+				}
+				// Every copy failed, or the rest would fail the same way
+				if inflight == 0 || ctx.Err() != nil || !retryable(ClassifyError(r.err)) {
+					return r.n, r.err
+				}
+			case <-timer.C:
+				if hedges >= p.MaxHedges {
+					continue
+				}
+				hedges++
+				inflight++
+				st.hedges.Add(1)
+				go launch(true)
+				timer.Reset(delay)
+			}
+		}
+	}
+}
+
+// retryable reports whether a failure class is worth retrying
+func retryable(class string) bool {
+	switch class {
+	case ErrClassCanceled, ErrClassTLS:
+		return false
+	}
+	if code, ok := strings.CutPrefix(class, ErrClassConnect+":"); ok {
+		switch code {
+		case "unavailable", "resource_exhausted", "aborted", "unknown", "internal", "deadline_exceeded":
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// attemptStats collects per-request policy outcomes, installed by DoRequest
+type attemptStats struct {
+	attempts atomic.Int32
+	hedges   atomic.Int32
+	hedgeWon atomic.Bool
+	firstNS  atomic.Int64
+}
+
+type attemptStatsKey struct{}
+
+func withAttemptStats(ctx context.Context, st *attemptStats) context.Context {
+	return context.WithValue(ctx, attemptStatsKey{}, st)
+}
+
+// attemptStatsFrom returns the stats carried by ctx, or a throwaway one
+// when the request function runs outside DoRequest
+func attemptStatsFrom(ctx context.Context) *attemptStats {
+	if st, ok := ctx.Value(attemptStatsKey{}).(*attemptStats); ok {
+		return st
+	}
+	return &attemptStats{}
+}
+
+// latencyTracker keeps a ring of recent successful latencies
+type latencyTracker struct {
+	mu      sync.Mutex
+	ring    []time.Duration
+	next    int
+	full    bool
+	cached  time.Duration
+	cachedQ float64
+	dirty   int
+}
+
+func newLatencyTracker(size int) *latencyTracker {
+	return &latencyTracker{ring: make([]time.Duration, size)}
+}
+
+func (t *latencyTracker) add(d time.Duration) {
+	t.mu.Lock()
+	t.ring[t.next] = d
+	t.next = (t.next + 1) % len(t.ring)
+	if t.next == 0 {
+		t.full = true
+	}
+	t.dirty++
+	t.mu.Unlock()
+}
+
+// quantile returns the q latency of recent requests, at least min.
+// Recomputed every 64 samples, min until 100 samples are seen
+func (t *latencyTracker) quantile(q float64, min time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.next
+	if t.full {
+		n = len(t.ring)
+	}
+	if n < 100 {
+		return min
+	}
+	if t.cached == 0 || t.cachedQ != q || t.dirty >= 64 {
+		s := make([]time.Duration, n)
+		copy(s, t.ring[:n])
+		sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+		idx := int(q * float64(n-1))
+		t.cached, t.cachedQ, t.dirty = s[idx], q, 0
+	}
+	if t.cached < min {
+		return min
+	}
+	return t.cached
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"h3-vs-h2-k6/echo/v1/echov1connect"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	bo := backoff{next: 10 * time.Millisecond, max: 50 * time.Millisecond}
+	bounds := []time.Duration{10, 20, 40, 50, 50, 50}
+	for i, b := range bounds {
+		b *= time.Millisecond
+		if d := bo.delay(); d < 0 || d >= b {
+			t.Errorf("delay %d = %v, want in [0, %v)", i, d, b)
+		}
+	}
+
+	zero := backoff{}
+	if d := zero.delay(); d != 0 {
+		t.Errorf("zero backoff delay = %v, want 0", d)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		class string
+		want  bool
+	}{
+		{ErrClassCanceled, false},
+		{ErrClassTLS, false},
+		{ErrClassConnReset, true},
+		{ErrClassDeadline, true},
+		{"h2_goaway:NO_ERROR", true},
+		{"connect:unavailable", true},
+		{"connect:resource_exhausted", true},
+		{"connect:invalid_argument", false},
+		{"connect:permission_denied", false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.class); got != tt.want {
+			t.Errorf("retryable(%q) = %v, want %v", tt.class, got, tt.want)
+		}
+	}
+}
+
+// failingRequest fails its first n calls with err, then succeeds
+func failingRequest(n int32, err error, calls *atomic.Int32) RequestFunc {
+	return func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		if calls.Add(1) <= n {
+			return 0, err
+		}
+		return 1, nil
+	}
+}
+
+func TestRetryRequest(t *testing.T) {
+	reset := syscall.ECONNRESET
+	tests := []struct {
+		name      string
+		failures  int32
+		err       error
+		retries   int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{"first attempt succeeds", 0, reset, 3, 1, false},
+		{"succeeds on retry", 2, reset, 3, 3, false},
+		{"retries exhausted", 10, reset, 2, 3, true},
+		{"not retryable", 10, connect.NewError(connect.CodeInvalidArgument, errors.New("bad")), 3, 1, true},
+		{"retries disabled", 10, reset, 0, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			fn := RetryRequest(failingRequest(tt.failures, tt.err, &calls), RetryPolicy{MaxRetries: tt.retries, Backoff: time.Millisecond, MaxBackoff: time.Millisecond})
+			st := &attemptStats{}
+			_, err := fn(withAttemptStats(context.Background(), st), nil, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if got := st.attempts.Load(); got != tt.wantCalls {
+				t.Errorf("attempts = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHedgeWinsOverSlowOriginal(t *testing.T) {
+	// The original hangs until canceled; the duplicate sent after the hedge
+	// delay answers and cancels it
+	var calls atomic.Int32
+	fn := func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 1, nil
+	}
+	tracker := newLatencyTracker(16)
+	hedged := hedgeRequest(fn, HedgePolicy{Percentile: 0.95, MinDelay: 5 * time.Millisecond, MaxHedges: 1}, tracker)
+
+	st := &attemptStats{}
+	ctx, cancel := context.WithTimeout(withAttemptStats(context.Background(), st), 5*time.Second)
+	defer cancel()
+	if _, err := hedged(ctx, nil, 1); err != nil {
+		t.Fatalf("err = %v, want success from the hedge", err)
+	}
+	if st.hedges.Load() != 1 || !st.hedgeWon.Load() {
+		t.Errorf("hedges = %d, hedgeWon = %v; want 1, true", st.hedges.Load(), st.hedgeWon.Load())
+	}
+	// Measured from the original send, so at least the hedge delay
+	if d := tracker.ring[0]; d < 5*time.Millisecond {
+		t.Errorf("tracked latency = %v, want >= 5ms", d)
+	}
+}
+
+func TestPolicyRetriesAfterHedgesFail(t *testing.T) {
+	// The original fails before the hedge delay; the re-send is a retry
+	// attempt, not a hedge
+	var calls atomic.Int32
+	p := &Policy{
+		Retry: RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Hedge: HedgePolicy{Percentile: 0.95, MinDelay: time.Hour, MaxHedges: 1},
+	}
+	fn := p.Wrap(failingRequest(1, syscall.ECONNRESET, &calls))
+
+	st := &attemptStats{}
+	ctx, cancel := context.WithTimeout(withAttemptStats(context.Background(), st), 5*time.Second)
+	defer cancel()
+	if _, err := fn(ctx, nil, 1); err != nil {
+		t.Fatalf("err = %v, want success from the retry", err)
+	}
+	if calls.Load() != 2 || st.attempts.Load() != 2 || st.hedges.Load() != 0 || st.hedgeWon.Load() {
+		t.Errorf("calls = %d, attempts = %d, hedges = %d, hedgeWon = %v; want 2, 2, 0, false",
+			calls.Load(), st.attempts.Load(), st.hedges.Load(), st.hedgeWon.Load())
+	}
+}
+
+func TestHedgeStopsOnNonRetryable(t *testing.T) {
+	// The original hangs; the first hedge fails with a code the remaining
+	// copies would fail with too, so no more hedges go out
+	var calls atomic.Int32
+	fn := func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 0, connect.NewError(connect.CodeInvalidArgument, errors.New("bad"))
+	}
+	hedged := HedgeRequest(fn, HedgePolicy{Percentile: 0.95, MinDelay: 20 * time.Millisecond, MaxHedges: 3})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := hedged(ctx, nil, 1)
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("err = %v, want invalid_argument", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2 (original and one hedge)", got)
+	}
+}
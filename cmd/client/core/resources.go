@@ -0,0 +1,198 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"sort"
+	"time"
+)
+
+// Resource sampling: one sample per interval, client-bound when CPU p90
+// reaches clientBoundCPUFrac of the cores available to the process
+const (
+	resourceInterval   = 1 * time.Second
+	clientBoundCPUFrac = 0.85
+)
+
+// ResourceSample is one snapshot of client process usage
+type ResourceSample struct {
+	TsUnixNS        int64
+	CPUPct          float64 // Process CPU over the interval, 100 = one core
+	RSSBytes        uint64
+	HeapBytes       uint64
+	Goroutines      int
+	GCPauseTotalms  float64 // Cumulative since the sampler started
+	UDPRcvbufErrors uint64  // Cumulative since the sampler started (host-wide)
+}
+
+// Resources summarizes client resource usage over a run
+type Resources struct {
+	Samples []ResourceSample
+
+	Cores           int     // GOMAXPROCS
+	CPUSeconds      float64 // User+system CPU time
+	CPUMeanPct      float64
+	CPUP90Pct       float64
+	CPUMaxPct       float64
+	PeakRSSBytes    uint64
+	PeakHeapBytes   uint64
+	MaxGoroutines   int
+	GCCount         uint32
+	GCPauseTotalms  float64
+	UDPInErrors     uint64 // Kernel UDP counters, host-wide (Linux only)
+	UDPRcvbufErrors uint64
+
+	ClientBound bool     // Results likely limited by the client, not the protocol
+	Reasons     []string // Why ClientBound was set
+}
+
+// ResourceSampler samples client resource usage until stopped
+type ResourceSampler struct {
+	useH3   bool
+	done    chan struct{}
+	stopped chan struct{}
+	res     Resources
+}
+
+// StartResourceSampler samples process CPU, memory, GC, goroutines and
+// kernel UDP drops every second until Stop or ctx is done. UDP drops only
+// flag H3 runs as client-bound; H2 does not use UDP
+func StartResourceSampler(ctx context.Context, useH3 bool) *ResourceSampler {
+	s := &ResourceSampler{useH3: useH3, done: make(chan struct{}), stopped: make(chan struct{})}
+	go s.run(ctx)
+	return s
+}
+
+// Stop ends sampling and returns the summary
+func (s *ResourceSampler) Stop() Resources {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	<-s.stopped
+	return s.res
+}
+
+// runtimeMetrics are read with runtime/metrics, which unlike
+// runtime.ReadMemStats does not stop the world during the measured run
+var runtimeMetrics = []metrics.Sample{
+	{Name: "/gc/cycles/total:gc-cycles"},
+	{Name: "/cpu/classes/gc/pause:cpu-seconds"}, // GOMAXPROCS x pause time
+	{Name: "/memory/classes/heap/objects:bytes"},
+	{Name: "/memory/classes/total:bytes"},
+}
+
+// runtimeStats is one read of runtimeMetrics
+type runtimeStats struct {
+	gcCycles  uint64
+	gcPauseNS float64
+	heapBytes uint64
+	sysBytes  uint64
+}
+
+func readRuntimeStats(samples []metrics.Sample) runtimeStats {
+	metrics.Read(samples)
+	u := func(i int) uint64 {
+		if samples[i].Value.Kind() != metrics.KindUint64 {
+			return 0
+		}
+		return samples[i].Value.Uint64()
+	}
+	var st runtimeStats
+	st.gcCycles = u(0)
+	if samples[1].Value.Kind() == metrics.KindFloat64 {
+		st.gcPauseNS = samples[1].Value.Float64() * 1e9 / float64(runtime.GOMAXPROCS(0))
+	}
+	st.heapBytes = u(2)
+	st.sysBytes = u(3)
+	return st
+}
+
+func (s *ResourceSampler) run(ctx context.Context) {
+	defer close(s.stopped)
+
+	rm := make([]metrics.Sample, len(runtimeMetrics))
+	copy(rm, runtimeMetrics)
+	rt := readRuntimeStats(rm)
+	startGC, startPauseNS := rt.gcCycles, rt.gcPauseNS
+	startCPU := processCPUTime()
+	startUDP := readUDPStats()
+
+	lastCPU, lastTS := startCPU, time.Now()
+	var samples []ResourceSample
+	take := func() {
+		now := time.Now()
+		cpu := processCPUTime()
+		rt = readRuntimeStats(rm)
+		udp := readUDPStats()
+
+		var pct float64
+		if dt := now.Sub(lastTS); dt > 0 {
+			pct = 100 * (cpu - lastCPU).Seconds() / dt.Seconds()
+		}
+		lastCPU, lastTS = cpu, now
+		samples = append(samples, ResourceSample{
+			TsUnixNS:        now.UnixNano(),
+			CPUPct:          Round6(pct),
+			RSSBytes:        processRSS(rt.sysBytes),
+			HeapBytes:       rt.heapBytes,
+			Goroutines:      runtime.NumGoroutine(),
+			GCPauseTotalms:  Round6((rt.gcPauseNS - startPauseNS) / 1e6),
+			UDPRcvbufErrors: udp.rcvbufErrors - startUDP.rcvbufErrors,
+		})
+	}
+
+	tk := time.NewTicker(resourceInterval)
+	defer tk.Stop()
+loop:
+	for {
+		select {
+		case <-tk.C:
+			take()
+		case <-ctx.Done():
+			break loop
+		case <-s.done:
+			break loop
+		}
+	}
+	take()
+
+	udp := readUDPStats()
+	r := Resources{
+		Samples:         samples,
+		Cores:           runtime.GOMAXPROCS(0),
+		CPUSeconds:      Round6((processCPUTime() - startCPU).Seconds()),
+		GCCount:         uint32(rt.gcCycles - startGC),
+		GCPauseTotalms:  Round6((rt.gcPauseNS - startPauseNS) / 1e6),
+		UDPInErrors:     udp.inErrors - startUDP.inErrors,
+		UDPRcvbufErrors: udp.rcvbufErrors - startUDP.rcvbufErrors,
+	}
+	cpus := make([]float64, 0, len(samples))
+	var sum float64
+	for _, smp := range samples {
+		cpus = append(cpus, smp.CPUPct)
+		sum += smp.CPUPct
+		r.PeakRSSBytes = max(r.PeakRSSBytes, smp.RSSBytes)
+		r.PeakHeapBytes = max(r.PeakHeapBytes, smp.HeapBytes)
+		r.MaxGoroutines = max(r.MaxGoroutines, smp.Goroutines)
+	}
+	sort.Float64s(cpus)
+	if len(cpus) > 0 {
+		r.CPUMeanPct = Round6(sum / float64(len(cpus)))
+		r.CPUP90Pct = Round6(Percentile(cpus, 0.90))
+		r.CPUMaxPct = cpus[len(cpus)-1]
+	}
+
+	capacity := 100 * float64(r.Cores)
+	if r.CPUP90Pct >= clientBoundCPUFrac*capacity {
+		r.Reasons = append(r.Reasons, fmt.Sprintf("client CPU saturated: p90 %.0f%% of %d cores", r.CPUP90Pct, r.Cores))
+	}
+	if s.useH3 && r.UDPRcvbufErrors > 0 {
+		r.Reasons = append(r.Reasons, fmt.Sprintf("kernel dropped %d UDP datagrams on full receive buffers (host-wide counter, may include other processes)", r.UDPRcvbufErrors))
+	}
+	r.ClientBound = len(r.Reasons) > 0
+	s.res = r
+}
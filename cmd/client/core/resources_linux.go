@@ -0,0 +1,79 @@
+//go:build linux
+
+package core
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// processCPUTime returns user+system CPU time of the process
+func processCPUTime() time.Duration {
+	var ru unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// processRSS returns the resident set size from /proc/self/statm
+func processRSS(fallback uint64) uint64 {
+	b, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return fallback
+	}
+	f := strings.Fields(string(b))
+	if len(f) < 2 {
+		return fallback
+	}
+	pages, err := strconv.ParseUint(f[1], 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return pages * uint64(os.Getpagesize())
+}
+
+type udpStats struct {
+	inErrors     uint64
+	rcvbufErrors uint64
+}
+
+// readUDPStats reads the kernel UDP counters from /proc/net/snmp
+func readUDPStats() udpStats {
+	f, err := os.Open("/proc/net/snmp")
+	if err != nil {
+		return udpStats{}
+	}
+	defer f.Close()
+
+	// Header line followed by value line, both prefixed "Udp:"
+	var header []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] != "Udp:" {
+			continue
+		}
+		if header == nil {
+			header = fields
+			continue
+		}
+		var st udpStats
+		for i := 1; i < len(fields) && i < len(header); i++ {
+			v, _ := strconv.ParseUint(fields[i], 10, 64)
+			switch header[i] {
+			case "InErrors":
+				st.inErrors = v
+			case "RcvbufErrors":
+				st.rcvbufErrors = v
+			}
+		}
+		return st
+	}
+	return udpStats{}
+}
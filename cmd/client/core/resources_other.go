@@ -0,0 +1,19 @@
+//go:build !linux
+
+package core
+
+import "time"
+
+// processCPUTime is only measured on Linux
+func processCPUTime() time.Duration { return 0 }
+
+// processRSS falls back to memory obtained from the OS by the Go runtime
+func processRSS(fallback uint64) uint64 { return fallback }
+
+type udpStats struct {
+	inErrors     uint64
+	rcvbufErrors uint64
+}
+
+// readUDPStats is only available on Linux (/proc/net/snmp)
+func readUDPStats() udpStats { return udpStats{} }
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestResourceSampler(t *testing.T) {
+	s := StartResourceSampler(context.Background(), false)
+	runtime.GC()
+	r := s.Stop()
+
+	if len(r.Samples) == 0 {
+		t.Fatal("no samples: Stop must take a final sample")
+	}
+	if r.Cores != runtime.GOMAXPROCS(0) {
+		t.Errorf("Cores = %d, want GOMAXPROCS %d", r.Cores, runtime.GOMAXPROCS(0))
+	}
+	if r.GCCount < 1 {
+		t.Errorf("GCCount = %d, want at least the forced GC", r.GCCount)
+	}
+	if r.PeakRSSBytes == 0 || r.PeakHeapBytes == 0 || r.MaxGoroutines == 0 {
+		t.Errorf("peak rss=%d heap=%d goroutines=%d, want non-zero", r.PeakRSSBytes, r.PeakHeapBytes, r.MaxGoroutines)
+	}
+	if r.ClientBound != (len(r.Reasons) > 0) {
+		t.Errorf("ClientBound = %v with reasons %v", r.ClientBound, r.Reasons)
+	}
+	if again := s.Stop(); len(again.Samples) != len(r.Samples) {
+		t.Error("a second Stop must return the same summary")
+	}
+}
+
+func TestResourceSamplerContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := StartResourceSampler(ctx, true)
+	cancel()
+	if r := s.Stop(); len(r.Samples) == 0 {
+		t.Error("no samples after ctx was canceled")
+	}
+}
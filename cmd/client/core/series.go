@@ -0,0 +1,117 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSeriesInterval is the time-series bucket width when no Window sets one
+const defaultSeriesInterval = time.Second
+
+// SeriesPoint holds latency stats of the requests started in one interval
+type SeriesPoint struct {
+	TsUnixNS   int64 // Interval start
+	Samples    int
+	ErrRatePct float64
+	P50ms      float64
+	P90ms      float64
+	P99ms      float64
+	Maxms      float64
+}
+
+// timeSeries buckets records by start time into fixed intervals.
+// Empty intervals are kept so gaps stay visible in charts
+func timeSeries(all []Record, interval time.Duration) []SeriesPoint {
+	if len(all) == 0 || interval <= 0 {
+		return nil
+	}
+	size := interval.Nanoseconds()
+
+	type bucket struct {
+		lat  []float64
+		errs int
+	}
+	buckets := make(map[int64]*bucket)
+	var minIdx, maxIdx int64
+	for i, r := range all {
+		idx := r.TsUnixNS / size
+		if i == 0 || idx < minIdx {
+			minIdx = idx
+		}
+		if i == 0 || idx > maxIdx {
+			maxIdx = idx
+		}
+		b := buckets[idx]
+		if b == nil {
+			b = &bucket{}
+			buckets[idx] = b
+		}
+		b.lat = append(b.lat, float64(r.LatencyNS)/1e6)
+		if !r.OK {
+			b.errs++
+		}
+	}
+
+	out := make([]SeriesPoint, 0, maxIdx-minIdx+1)
+	for idx := minIdx; idx <= maxIdx; idx++ {
+		p := SeriesPoint{TsUnixNS: idx * size}
+		if b := buckets[idx]; b != nil {
+			sort.Float64s(b.lat)
+			p.Samples = len(b.lat)
+			p.ErrRatePct = 100 * float64(b.errs) / float64(len(b.lat))
+			p.P50ms = Round6(Percentile(b.lat, 0.50))
+			p.P90ms = Round6(Percentile(b.lat, 0.90))
+			p.P99ms = Round6(Percentile(b.lat, 0.99))
+			p.Maxms = Round6(b.lat[len(b.lat)-1])
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// SeriesCSVPath derives the time-series CSV path from the raw CSV path
+// (results/run.csv -> results/run_series.csv)
+func SeriesCSVPath(csvPath string) string {
+	ext := filepath.Ext(csvPath)
+	return strings.TrimSuffix(csvPath, ext) + "_series" + ext
+}
+
+// WriteSeriesCSV writes the per-interval latency series to CSV file
+func WriteSeriesCSV(path string, s Summary, logger *Logger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	ff := func(v float64) string { return strconv.FormatFloat(v, 'f', 6, 64) }
+	_ = w.Write([]string{"ts_unix_ns", "interval_s", "samples", "err_rate_pct", "p50_ms", "p90_ms", "p99_ms", "max_ms"})
+	for _, p := range s.Series {
+		_ = w.Write([]string{
+			strconv.FormatInt(p.TsUnixNS, 10),
+			ff(s.SeriesIntervalS),
+			strconv.Itoa(p.Samples),
+			ff(p.ErrRatePct),
+			ff(p.P50ms),
+			ff(p.P90ms),
+			ff(p.P99ms),
+			ff(p.Maxms),
+		})
+	}
+
+	logger.Info("Series CSV written: %s (%d intervals)", path, len(s.Series))
+	return nil
+}
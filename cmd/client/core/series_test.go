@@ -0,0 +1,52 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeSeries(t *testing.T) {
+	const start = int64(1_700_000_000) * 1e9
+	sec := time.Second.Nanoseconds()
+	var rs []Record
+	// Interval 0: 1..10 ms, one failure (percentiles interpolate). Interval 1: empty. Interval 2: two at 50 ms
+	for i := 1; i <= 10; i++ {
+		rs = append(rs, Record{TsUnixNS: start + int64(i)*1e6, LatencyNS: int64(i) * 1e6, OK: i != 10})
+	}
+	rs = append(rs,
+		Record{TsUnixNS: start + 2*sec + 1, LatencyNS: 50e6, OK: true},
+		Record{TsUnixNS: start + 2*sec + 2, LatencyNS: 50e6, OK: true},
+	)
+
+	got := timeSeries(rs, time.Second)
+	want := []SeriesPoint{
+		{TsUnixNS: start, Samples: 10, ErrRatePct: 10, P50ms: 5.5, P90ms: 9.1, P99ms: 9.91, Maxms: 10},
+		{TsUnixNS: start + sec},
+		{TsUnixNS: start + 2*sec, Samples: 2, P50ms: 50, P90ms: 50, P99ms: 50, Maxms: 50},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if timeSeries(nil, time.Second) != nil || timeSeries(rs, 0) != nil {
+		t.Error("timeSeries without records or interval should be nil")
+	}
+}
+
+func TestSeriesCSVPath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"results/run.csv", "results/run_series.csv"},
+		{"run", "run_series"},
+		{"a.b/run.v2.csv", "a.b/run.v2_series.csv"},
+	}
+	for _, tt := range tests {
+		if got := SeriesCSVPath(tt.in); got != tt.want {
+			t.Errorf("SeriesCSVPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"h3-vs-h2-k6/internal/admin"
+)
+
+// ServerStats brackets a run with the server's admin run lifecycle API
+type ServerStats struct {
+	URL string // Admin base URL, e.g. http://localhost:9443 (empty = off)
+
+	runID string // Run opened on the server, empty when none
+}
+
+// RegisterServerStatsFlags registers the server admin flag on fs
+func RegisterServerStatsFlags(fs *flag.FlagSet) *ServerStats {
+	s := &ServerStats{}
+	fs.StringVar(&s.URL, "server-admin", "", "server admin endpoint (server --admin-addr); resets server counters at run start and reports server CPU and bytes per request, e.g. http://localhost:9443")
+	return s
+}
+
+// Fields returns the server stats settings for startup logs and results
+func (s *ServerStats) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"server_admin": s.URL,
+	}
+}
+
+// ServerCost is the server-side cost of a run, per request handled
+type ServerCost struct {
+	Protocol          string
+	Requests          uint64  // Requests the server handled during the run
+	CPUSeconds        float64 // User+system
+	CPUusPerReq       float64
+	AllocsPerReq      float64
+	AllocBytesPerReq  float64
+	CtxSwitchesPerReq float64
+	GCCount           uint32
+	Conns             uint64  // Connections accepted during the run
+	WireInPerReq      float64 // Bytes received on the wire (TLS records or QUIC packets)
+	WireOutPerReq     float64
+	PacketsPerReq     float64 // QUIC packets in+out
+	AppBytesPerReq    float64 // Payload bytes in+out seen by handlers
+	WireOverheadPct   float64 // Wire bytes beyond payload, relative to payload; negative when Connect compression shrinks responses
+}
+
+// StartRun resets the server counters and opens a run under runID
+func (s *ServerStats) StartRun(runID string, logger *Logger) {
+	if s.URL == "" {
+		return
+	}
+	if s.post(admin.RunStartPath, runID, logger) != nil {
+		s.runID = runID
+		logger.Info("server admin: run %s started", runID)
+	}
+}
+
+// EndRun closes the run opened by StartRun and returns its cost
+func (s *ServerStats) EndRun(logger *Logger) ServerCost {
+	if s.runID == "" {
+		return ServerCost{}
+	}
+	snap := s.post(admin.RunEndPath, s.runID, logger)
+	s.runID = ""
+	if snap == nil {
+		return ServerCost{}
+	}
+	return costOf(snap)
+}
+
+// post calls a run lifecycle route, nil when the server is unreachable or refuses
+func (s *ServerStats) post(path, runID string, logger *Logger) *admin.Snapshot {
+	cl := &http.Client{Timeout: 5 * time.Second}
+	u := strings.TrimSuffix(s.URL, "/") + path + "?run_id=" + url.QueryEscape(runID)
+	resp, err := cl.Post(u, "", nil)
+	if err != nil {
+		logger.Info("server admin %s failed: %v", path, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		logger.Info("server admin %s failed: %s %s", path, resp.Status, strings.TrimSpace(string(msg)))
+		return nil
+	}
+	var snap admin.Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		logger.Info("server admin %s failed: %v", path, err)
+		return nil
+	}
+	return &snap
+}
+
+// costOf turns a run snapshot into per-request cost
+func costOf(snap *admin.Snapshot) ServerCost {
+	c := ServerCost{
+		Protocol:   snap.Protocol,
+		Requests:   snap.Requests,
+		CPUSeconds: Round6(snap.CPUUserSeconds + snap.CPUSysSeconds),
+		GCCount:    snap.GCCount,
+		Conns:      snap.Conns,
+	}
+	if c.Requests == 0 {
+		return c
+	}
+	n := float64(c.Requests)
+	perReq := func(v uint64) float64 { return Round6(float64(v) / n) }
+	c.CPUusPerReq = Round6(c.CPUSeconds * 1e6 / n)
+	c.AllocsPerReq = perReq(snap.Allocs)
+	c.AllocBytesPerReq = perReq(snap.AllocBytes)
+	c.CtxSwitchesPerReq = perReq(snap.CtxSwitches)
+	c.WireInPerReq = perReq(snap.WireBytesIn)
+	c.WireOutPerReq = perReq(snap.WireBytesOut)
+	c.PacketsPerReq = perReq(snap.PacketsIn + snap.PacketsOut)
+	c.AppBytesPerReq = perReq(snap.AppBytesIn + snap.AppBytesOut)
+	if c.AppBytesPerReq > 0 {
+		c.WireOverheadPct = Round6(100 * (c.WireInPerReq + c.WireOutPerReq - c.AppBytesPerReq) / c.AppBytesPerReq)
+	}
+	return c
+}
+
+// String formats the per-request cost for logs
+func (c ServerCost) String() string {
+	return fmt.Sprintf("%s requests=%d cpu=%.3fs cpu_per_req=%.1fus allocs_per_req=%.1f alloc_bytes_per_req=%.0f ctx_switches_per_req=%.2f wire_in_per_req=%.0fB wire_out_per_req=%.0fB packets_per_req=%.2f overhead=%.1f%% conns=%d",
+		c.Protocol, c.Requests, c.CPUSeconds, c.CPUusPerReq, c.AllocsPerReq, c.AllocBytesPerReq, c.CtxSwitchesPerReq,
+		c.WireInPerReq, c.WireOutPerReq, c.PacketsPerReq, c.WireOverheadPct, c.Conns)
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"testing"
+
+	"h3-vs-h2-k6/internal/admin"
+)
+
+func TestCostOf(t *testing.T) {
+	tests := []struct {
+		name string
+		snap admin.Snapshot
+		want ServerCost
+	}{
+		{
+			name: "per-request cost",
+			snap: admin.Snapshot{
+				Protocol: "h2", Requests: 1000,
+				CPUUserSeconds: 1.5, CPUSysSeconds: 0.5,
+				Allocs: 50_000, AllocBytes: 4_000_000, GCCount: 3, CtxSwitches: 2500,
+				WireBytesIn: 300_000, WireBytesOut: 900_000, PacketsIn: 1500, PacketsOut: 2500, Conns: 4,
+				AppBytesIn: 200_000, AppBytesOut: 800_000,
+			},
+			want: ServerCost{
+				Protocol: "h2", Requests: 1000, CPUSeconds: 2, GCCount: 3, Conns: 4,
+				CPUusPerReq: 2000, AllocsPerReq: 50, AllocBytesPerReq: 4000, CtxSwitchesPerReq: 2.5,
+				WireInPerReq: 300, WireOutPerReq: 900, PacketsPerReq: 4, AppBytesPerReq: 1000,
+				WireOverheadPct: 20,
+			},
+		},
+		{
+			name: "no requests",
+			snap: admin.Snapshot{Protocol: "h3", CPUUserSeconds: 0.25, GCCount: 1, Conns: 1, WireBytesIn: 1200},
+			want: ServerCost{Protocol: "h3", CPUSeconds: 0.25, GCCount: 1, Conns: 1},
+		},
+		{
+			name: "no app bytes",
+			snap: admin.Snapshot{Protocol: "h3", Requests: 10, WireBytesIn: 100},
+			want: ServerCost{Protocol: "h3", Requests: 10, WireInPerReq: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := costOf(&tt.snap); got != tt.want {
+				t.Errorf("costOf = %+v\nwant     %+v", got, tt.want)
+			}
+		})
+	}
+}
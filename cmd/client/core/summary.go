@@ -7,12 +7,21 @@ import (
 
 // Summarize aggregates raw records into summary statistics
 func Summarize(all []Record) Summary {
+	s := summarize(all)
+	s.Classes = summarizeClasses(all)
+	s.Series = timeSeries(all, defaultSeriesInterval)
+	s.SeriesIntervalS = defaultSeriesInterval.Seconds()
+	return s
+}
+
+// summarize computes everything but the per-class breakdown and time series
+func summarize(all []Record) Summary {
 	if len(all) == 0 {
 		return Summary{}
 	}
 
 	var minTS, maxTS int64 = math.MaxInt64, math.MinInt64
-	var okCount int
+	var okCount, timeouts int
 	latms := make([]float64, 0, len(all))
 	var sum float64
 	min := math.MaxFloat64
@@ -28,6 +37,9 @@ func Summarize(all []Record) Summary {
 		if r.OK {
 			okCount++
 		}
+		if r.Timeout {
+			timeouts++
+		}
 		ms := float64(r.LatencyNS) / 1e6
 		latms = append(latms, ms)
 		sum += ms
@@ -46,15 +58,7 @@ func Summarize(all []Record) Summary {
 	rps := float64(len(all)) / durationS
 
 	sort.Float64s(latms)
-	percentile := func(p float64) float64 {
-		pos := p * float64(len(latms)-1)
-		i := int(pos)
-		f := pos - float64(i)
-		if i+1 < len(latms) {
-			return latms[i] + f*(latms[i+1]-latms[i])
-		}
-		return latms[i]
-	}
+	percentile := func(p float64) float64 { return Percentile(latms, p) }
 
 	// CDF
 	y := make([]float64, len(latms))
@@ -62,7 +66,35 @@ func Summarize(all []Record) Summary {
 		y[i] = float64(i+1) / float64(len(latms))
 	}
 
-	// Throughput per second
+	ts, val := throughput(all)
+
+	return Summary{
+		Samples:    len(all),
+		OKRatePct:  100 * float64(okCount) / float64(len(all)),
+		Timeouts:   timeouts,
+		TimeoutPct: 100 * float64(timeouts) / float64(len(all)),
+		RPS:        rps,
+		DurationS:  durationS,
+		P50ms:      Round6(percentile(0.50)),
+		P90ms:      Round6(percentile(0.90)),
+		P95ms:      Round6(percentile(0.95)),
+		P99ms:      Round6(percentile(0.99)),
+		Meanms:     Round6(sum / float64(len(all))),
+		Minms:      Round6(min),
+		Maxms:      Round6(max),
+		CDF_X_ms:   latms,
+		CDF_Y:      y,
+		THR_Ts:     ts,
+		THR_Val:    val,
+
+		ErrorClasses: ErrorClassCounts(all),
+		Resilience:   summarizeResilience(all),
+		Phases:       summarizePhases(all),
+	}
+}
+
+// throughput counts requests per second of start time
+func throughput(all []Record) ([]int64, []int) {
 	m := make(map[int64]int)
 	var minSec, maxSec int64 = math.MaxInt64, math.MinInt64
 	for _, r := range all {
@@ -88,24 +120,62 @@ func Summarize(all []Record) Summary {
 			val[i] = m[s]
 		}
 	}
+	return ts, val
+}
 
-	return Summary{
-		Samples:   len(all),
-		OKRatePct: 100 * float64(okCount) / float64(len(all)),
-		RPS:       rps,
-		DurationS: durationS,
-		P50ms:     Round6(percentile(0.50)),
-		P90ms:     Round6(percentile(0.90)),
-		P95ms:     Round6(percentile(0.95)),
-		P99ms:     Round6(percentile(0.99)),
-		Meanms:    Round6(sum / float64(len(all))),
-		Minms:     Round6(min),
-		Maxms:     Round6(max),
-		CDF_X_ms:  latms,
-		CDF_Y:     y,
-		THR_Ts:    ts,
-		THR_Val:   val,
+// summarizeResilience aggregates first-attempt latency, attempts and hedge wins
+func summarizeResilience(all []Record) Resilience {
+	first := make([]float64, 0, len(all))
+	var attempts, retried, hedged, won int
+	for _, r := range all {
+		n := r.Attempts
+		if n == 0 {
+			n = 1
+		}
+		attempts += n
+		if n > 1 {
+			retried++
+		}
+		if r.Hedges > 0 {
+			hedged++
+			if r.HedgeWon {
+				won++
+			}
+		}
+		ns := r.FirstLatencyNS
+		if ns == 0 {
+			ns = r.LatencyNS
+		}
+		first = append(first, float64(ns)/1e6)
+	}
+	sort.Float64s(first)
+
+	res := Resilience{
+		FirstP50ms:   Round6(Percentile(first, 0.50)),
+		FirstP90ms:   Round6(Percentile(first, 0.90)),
+		FirstP99ms:   Round6(Percentile(first, 0.99)),
+		AttemptsMean: Round6(float64(attempts) / float64(len(all))),
+		RetriedPct:   100 * float64(retried) / float64(len(all)),
+		HedgedPct:    100 * float64(hedged) / float64(len(all)),
+	}
+	if hedged > 0 {
+		res.HedgeWinRatePct = 100 * float64(won) / float64(hedged)
+	}
+	return res
+}
+
+// Percentile interpolates the p quantile of sorted values
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	i := int(pos)
+	f := pos - float64(i)
+	if i+1 < len(sorted) {
+		return sorted[i] + f*(sorted[i+1]-sorted[i])
 	}
+	return sorted[i]
 }
 
 // Round6 rounds float to 6 decimal places
@@ -0,0 +1,282 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SweepParam is one swept parameter and its values, in run order
+type SweepParam struct {
+	Name   string
+	Values []string
+}
+
+// ParseSweepParam parses "name=v1,v2,..."
+func ParseSweepParam(s string) (SweepParam, error) {
+	name, list, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return SweepParam{}, fmt.Errorf("invalid sweep parameter %q, want name=v1,v2", s)
+	}
+	p := SweepParam{Name: name}
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			p.Values = append(p.Values, v)
+		}
+	}
+	if len(p.Values) == 0 {
+		return SweepParam{}, fmt.Errorf("sweep parameter %q has no values", name)
+	}
+	return p, nil
+}
+
+// SweepPoint is the headline outcome of one scenario run in a sweep
+type SweepPoint struct {
+	Protocol  string
+	Params    map[string]string // Swept parameter values of this run
+	Samples   int
+	OKRatePct float64
+	RPS       float64
+	P50ms     float64
+	P90ms     float64
+	P95ms     float64
+	P99ms     float64
+	Meanms    float64
+	Maxms     float64
+	File      string // Per-run JSON result
+	Error     string // Run failure, metrics are zero
+}
+
+// NewSweepPoint takes the headline stats of a run's summary
+func NewSweepPoint(protocol string, params map[string]string, s Summary) SweepPoint {
+	return SweepPoint{
+		Protocol:  protocol,
+		Params:    params,
+		Samples:   s.Samples,
+		OKRatePct: s.OKRatePct,
+		RPS:       s.RPS,
+		P50ms:     s.P50ms,
+		P90ms:     s.P90ms,
+		P95ms:     s.P95ms,
+		P99ms:     s.P99ms,
+		Meanms:    s.Meanms,
+		Maxms:     s.Maxms,
+	}
+}
+
+// ParamString formats the point's parameter values as "name=value ..."
+func (p SweepPoint) ParamString(params []SweepParam) string {
+	kv := make([]string, 0, len(params))
+	for _, sp := range params {
+		kv = append(kv, sp.Name+"="+p.Params[sp.Name])
+	}
+	return strings.Join(kv, " ")
+}
+
+// SweepResult is the combined output of a parameter sweep
+type SweepResult struct {
+	Label    string
+	Scenario string
+	Params   []SweepParam // First parameter is the x axis, second splits the lines
+	Points   []SweepPoint // In run order
+	Meta     map[string]string
+}
+
+// SweepPoint logs the outcome of one sweep run
+func (l *Logger) SweepPoint(p SweepPoint, params []SweepParam) {
+	if l.level < LogLevelMinimal {
+		return
+	}
+	if p.Error != "" {
+		log.Printf("sweep | %s %s error=%q", p.Protocol, p.ParamString(params), p.Error)
+		return
+	}
+	log.Printf("sweep | %s %s samples=%d ok_rate=%.2f%% rps=%.2f p50=%.3fms p90=%.3fms p99=%.3fms",
+		p.Protocol, p.ParamString(params), p.Samples, p.OKRatePct, p.RPS, p.P50ms, p.P90ms, p.P99ms)
+}
+
+// WriteSweepJSON writes the combined sweep result as JSON
+func WriteSweepJSON(path string, r SweepResult, logger *Logger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+
+	logger.Info("Sweep JSON written: %s", path)
+	return nil
+}
+
+// WriteSweepHTML renders line charts of latency percentiles, throughput and
+// success rate against the first swept parameter, one line per protocol
+// (and per value of the second parameter)
+func WriteSweepHTML(path string, r SweepResult, logger *Logger) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(path), err)
+	}
+	if len(r.Params) == 0 {
+		return fmt.Errorf("sweep has no parameters")
+	}
+
+	// One line per protocol x second-parameter value over the first
+	// parameter's values; failed or missing runs leave a gap
+	x := r.Params[0]
+	split := []string{""}
+	if len(r.Params) > 1 {
+		split = r.Params[1].Values
+	}
+	var protocols []string
+	seen := map[string]bool{}
+	for _, p := range r.Points {
+		if !seen[p.Protocol] {
+			seen[p.Protocol] = true
+			protocols = append(protocols, p.Protocol)
+		}
+	}
+	metrics := []struct {
+		id, title string
+		yMax      *float64
+		f         func(p SweepPoint) float64
+	}{
+		{"p50", "Latency (ms)", nil, func(p SweepPoint) float64 { return p.P50ms }},
+		{"p90", "Latency (ms)", nil, func(p SweepPoint) float64 { return p.P90ms }},
+		{"p99", "Latency (ms)", nil, func(p SweepPoint) float64 { return p.P99ms }},
+		{"rps", "Requests/sec", nil, func(p SweepPoint) float64 { return p.RPS }},
+		{"ok", "OK %", Float(100), func(p SweepPoint) float64 { return p.OKRatePct }},
+	}
+	charts := map[string]template.HTML{}
+	for _, m := range metrics {
+		c := Chart{XTitle: x.Name, YTitle: m.title, XCategories: x.Values, YMin: Float(0), YMax: m.yMax}
+		for _, proto := range protocols {
+			for _, sv := range split {
+				l := ChartLine{Label: proto, Markers: true}
+				if sv != "" {
+					l.Label = fmt.Sprintf("%s %s=%s", proto, r.Params[1].Name, sv)
+				}
+				for i, xv := range x.Values {
+					pt := ChartPoint{X: float64(i), Y: math.NaN()}
+					for _, p := range r.Points {
+						if p.Protocol != proto || p.Error != "" || p.Params[x.Name] != xv {
+							continue
+						}
+						if sv != "" && p.Params[r.Params[1].Name] != sv {
+							continue
+						}
+						pt.Y = m.f(p)
+					}
+					l.Points = append(l.Points, pt)
+				}
+				c.Lines = append(c.Lines, l)
+			}
+		}
+		charts[m.id] = c.SVG()
+	}
+
+	type row struct {
+		Protocol string
+		Params   string
+		P        SweepPoint
+	}
+	var rows []row
+	for _, p := range r.Points {
+		rows = append(rows, row{Protocol: p.Protocol, Params: p.ParamString(r.Params), P: p})
+	}
+
+	data := struct {
+		Title  string
+		R      SweepResult
+		Rows   []row
+		Charts map[string]template.HTML
+	}{
+		Title:  r.Label,
+		R:      r,
+		Rows:   rows,
+		Charts: charts,
+	}
+
+	t, err := template.New("sweep").Parse(sweepHTMLTemplate)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := t.Execute(f, data); err != nil {
+		return err
+	}
+
+	logger.Info("Sweep HTML written: %s", path)
+	return nil
+}
+
+const sweepHTMLTemplate = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width,initial-scale=1">
+<title>{{ .Title }} – Parameter Sweep</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Helvetica, Arial, sans-serif; margin: 24px; }
+h1 { margin-bottom: 0; }
+.sub { color: #666; margin-top: 4px; }
+table { border-collapse: collapse; margin-top: 16px; }
+td, th { border: 1px solid #ddd; padding: 6px 10px; text-align: left; }
+.grid { display: grid; grid-template-columns: 1fr; gap: 16px; margin-top: 18px; }
+.chart { width: 100%; height: auto; }
+.warn { color: #b00020; }
+@media (min-width: 900px) { .grid { grid-template-columns: 1fr 1fr; } }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<div class="sub">Scenario: {{ .R.Scenario }} · {{ range $i, $p := .R.Params }}{{ if $i }} × {{ end }}{{ $p.Name }} = {{ range $j, $v := $p.Values }}{{ if $j }}, {{ end }}{{ $v }}{{ end }}{{ end }}</div>
+
+<div class="grid">
+<div><h3>p50 latency</h3>{{ index .Charts "p50" }}</div>
+<div><h3>p90 latency</h3>{{ index .Charts "p90" }}</div>
+<div><h3>p99 latency</h3>{{ index .Charts "p99" }}</div>
+<div><h3>Throughput</h3>{{ index .Charts "rps" }}</div>
+<div><h3>Success rate</h3>{{ index .Charts "ok" }}</div>
+</div>
+
+<h2>Runs</h2>
+<table>
+<thead><tr><th>Protocol</th><th>Parameters</th><th>Samples</th><th>OK %</th><th>RPS</th><th>p50 (ms)</th><th>p90 (ms)</th><th>p95 (ms)</th><th>p99 (ms)</th><th>Max (ms)</th><th>Result</th></tr></thead>
+{{ range .Rows }}
+<tr><td>{{ .Protocol }}</td><td>{{ .Params }}</td>
+{{ if .P.Error }}<td colspan="8"></td><td class="warn">{{ .P.Error }}</td>
+{{ else }}<td>{{ .P.Samples }}</td><td>{{ printf "%.2f" .P.OKRatePct }}</td><td>{{ printf "%.2f" .P.RPS }}</td><td>{{ printf "%.3f" .P.P50ms }}</td><td>{{ printf "%.3f" .P.P90ms }}</td><td>{{ printf "%.3f" .P.P95ms }}</td><td>{{ printf "%.3f" .P.P99ms }}</td><td>{{ printf "%.3f" .P.Maxms }}</td><td>{{ .P.File }}</td>{{ end }}</tr>
+{{ end }}
+</table>
+
+{{ if .R.Meta }}
+<h2>Configuration</h2>
+<table>
+{{ range $k, $v := .R.Meta }}<tr><td>{{ $k }}</td><td>{{ $v }}</td></tr>
+{{ end }}
+</table>
+{{ end }}
+
+</body>
+</html>
+`
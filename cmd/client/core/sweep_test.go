@@ -0,0 +1,45 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSweepParam(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    SweepParam
+		wantErr string
+	}{
+		{"payload=128,512,4096", SweepParam{Name: "payload", Values: []string{"128", "512", "4096"}}, ""},
+		{" loss = 0, 1 ,5,", SweepParam{Name: "loss", Values: []string{"0", "1", "5"}}, ""},
+		{"h2-encoder-table-size=1", SweepParam{Name: "h2-encoder-table-size", Values: []string{"1"}}, ""},
+		{"workers", SweepParam{}, "want name=v1,v2"},
+		{"=1,2", SweepParam{}, "want name=v1,v2"},
+		{"rps=", SweepParam{}, "no values"},
+		{"rps= , ,", SweepParam{}, "no values"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSweepParam(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSweepParam(%q) error = %v, want containing %q", tt.in, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSweepParam(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepPointParamString(t *testing.T) {
+	params := []SweepParam{{Name: "payload"}, {Name: "workers"}}
+	p := SweepPoint{Params: map[string]string{"workers": "8", "payload": "512"}}
+	if got, want := p.ParamString(params), "payload=512 workers=8"; got != want {
+		t.Errorf("ParamString = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// TableSweepPoint is the outcome of one header table size in a header-bloat sweep
+type TableSweepPoint struct {
+	TableSize         int // HPACK encoder table size in bytes (0 = x/net default)
+	Codec             string
+	Samples           int
+	OKRatePct         float64
+	RPS               float64
+	P50ms             float64
+	P99ms             float64
+	HeaderBytesPerReq float64 // Mean encoded request header block (estimate, see HeaderCompression)
+	HeaderRatio       float64 // Encoded/raw request header bytes (estimate)
+	IndexedPct        float64 // Header fields sent as a table index
+	WireInPerReq      float64 // Server-side wire bytes, needs --server-admin
+	WireOutPerReq     float64
+}
+
+// TableSizeClass names the request class of one sweep step
+func TableSizeClass(size int) string {
+	return "table=" + strconv.Itoa(size)
+}
+
+// ParseTableSizes parses a comma-separated list of table sizes in bytes
+func ParseTableSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid table size %q", f)
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes, nil
+}
+
+// NewTableSweepPoint combines a step's latency, header and server stats
+func NewTableSweepPoint(size int, c ClassSummary, h HeaderStats, srv ServerCost) TableSweepPoint {
+	return TableSweepPoint{
+		TableSize:         size,
+		Codec:             h.Codec,
+		Samples:           c.Samples,
+		OKRatePct:         c.OKRatePct,
+		RPS:               c.RPS,
+		P50ms:             c.P50ms,
+		P99ms:             c.P99ms,
+		HeaderBytesPerReq: h.MeanBlockBytes,
+		HeaderRatio:       h.Ratio,
+		IndexedPct:        Round6(h.IndexedPct()),
+		WireInPerReq:      srv.WireInPerReq,
+		WireOutPerReq:     srv.WireOutPerReq,
+	}
+}
+
+// TableSweep logs one line per table size of a header-bloat sweep
+func (l *Logger) TableSweep(s Summary) {
+	if l.level < LogLevelMinimal {
+		return
+	}
+	for _, p := range s.TableSweep {
+		log.Printf("table-sweep | %s table=%d samples=%d ok_rate=%.2f%% rps=%.2f p50=%.3fms p99=%.3fms est_header_bytes_per_req=%.0f est_ratio=%.3f indexed=%.1f%% wire_in_per_req=%.0fB wire_out_per_req=%.0fB",
+			p.Codec, p.TableSize, p.Samples, p.OKRatePct, p.RPS, p.P50ms, p.P99ms, p.HeaderBytesPerReq, p.HeaderRatio, p.IndexedPct, p.WireInPerReq, p.WireOutPerReq)
+	}
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThresholdExitCode is the exit code of a run that failed a threshold
+const ThresholdExitCode = 99
+
+// Threshold is a pass/fail check on a summary metric, e.g. "p99_ms<200"
+type Threshold struct {
+	Expr   string
+	Metric string
+	Op     string
+	Value  float64
+}
+
+// CheckResult is the outcome of one threshold
+type CheckResult struct {
+	Name   string // Threshold expression
+	Metric string
+	Actual float64
+	Pass   bool
+	Error  string // Metric unknown or not measured in this run
+}
+
+// Message describes a failed check
+func (c CheckResult) Message() string {
+	if c.Error != "" {
+		return c.Error
+	}
+	return fmt.Sprintf("%s = %s, want %s", c.Metric, formatTick(c.Actual, 0.001), strings.TrimPrefix(c.Name, c.Metric))
+}
+
+// thresholdMetrics are the metrics thresholds can refer to. ok is false when
+// the run did not measure the metric (e.g. server cost without --server-admin)
+var thresholdMetrics = map[string]func(s Summary) (v float64, ok bool){
+	"samples":     func(s Summary) (float64, bool) { return float64(s.Samples), true },
+	"ok_rate_pct": func(s Summary) (float64, bool) { return s.OKRatePct, true },
+	"error_rate_pct": func(s Summary) (float64, bool) {
+		return 100 - s.OKRatePct, s.Samples > 0
+	},
+	"timeout_pct": func(s Summary) (float64, bool) { return s.TimeoutPct, true },
+	"rps":         func(s Summary) (float64, bool) { return s.RPS, true },
+	"p50_ms":      func(s Summary) (float64, bool) { return s.P50ms, s.Samples > 0 },
+	"p90_ms":      func(s Summary) (float64, bool) { return s.P90ms, s.Samples > 0 },
+	"p95_ms":      func(s Summary) (float64, bool) { return s.P95ms, s.Samples > 0 },
+	"p99_ms":      func(s Summary) (float64, bool) { return s.P99ms, s.Samples > 0 },
+	"mean_ms":     func(s Summary) (float64, bool) { return s.Meanms, s.Samples > 0 },
+	"max_ms":      func(s Summary) (float64, bool) { return s.Maxms, s.Samples > 0 },
+
+	"client_cpu_mean_pct": func(s Summary) (float64, bool) { return s.Resources.CPUMeanPct, len(s.Resources.Samples) > 0 },
+	"server_cpu_us_per_req": func(s Summary) (float64, bool) {
+		return s.Server.CPUusPerReq, s.Server.Requests > 0
+	},
+	"server_wire_in_per_req": func(s Summary) (float64, bool) {
+		return s.Server.WireInPerReq, s.Server.Requests > 0
+	},
+	"server_wire_out_per_req": func(s Summary) (float64, bool) {
+		return s.Server.WireOutPerReq, s.Server.Requests > 0
+	},
+
+	"capacity_knee_rps": func(s Summary) (float64, bool) {
+		return float64(s.Capacity.KneeRPS), s.Capacity.Active()
+	},
+	"capacity_knee_p99_ms": func(s Summary) (float64, bool) {
+		return s.Capacity.KneeP99ms, s.Capacity.KneeRPS > 0
+	},
+}
+
+// ThresholdMetrics lists the metric names thresholds accept
+func ThresholdMetrics() []string {
+	names := make([]string, 0, len(thresholdMetrics))
+	for k := range thresholdMetrics {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseThreshold parses "metric<op>value" with op one of <, <=, >, >=, ==
+func ParseThreshold(expr string) (Threshold, error) {
+	e := strings.ReplaceAll(expr, " ", "")
+	i := strings.IndexAny(e, "<>=")
+	if i <= 0 {
+		return Threshold{}, fmt.Errorf("invalid threshold %q, want e.g. p99_ms<200", expr)
+	}
+	t := Threshold{Expr: e, Metric: e[:i]}
+	rest := e[i:]
+	for _, op := range []string{"<=", ">=", "==", "<", ">"} {
+		if strings.HasPrefix(rest, op) {
+			t.Op, rest = op, rest[len(op):]
+			break
+		}
+	}
+	if t.Op == "" {
+		return Threshold{}, fmt.Errorf("invalid threshold %q: unknown operator", expr)
+	}
+	if _, ok := thresholdMetrics[t.Metric]; !ok {
+		return Threshold{}, fmt.Errorf("invalid threshold %q: unknown metric %q (one of %s)", expr, t.Metric, strings.Join(ThresholdMetrics(), ", "))
+	}
+	v, err := strconv.ParseFloat(rest, 64)
+	if err != nil {
+		return Threshold{}, fmt.Errorf("invalid threshold %q: %v", expr, err)
+	}
+	t.Value = v
+	return t, nil
+}
+
+// Check evaluates the threshold against a summary
+func (t Threshold) Check(s Summary) CheckResult {
+	c := CheckResult{Name: t.Expr, Metric: t.Metric}
+	v, ok := thresholdMetrics[t.Metric](s)
+	if !ok {
+		c.Error = fmt.Sprintf("%s not measured in this run", t.Metric)
+		return c
+	}
+	c.Actual = Round6(v)
+	switch t.Op {
+	case "<":
+		c.Pass = v < t.Value
+	case "<=":
+		c.Pass = v <= t.Value
+	case ">":
+		c.Pass = v > t.Value
+	case ">=":
+		c.Pass = v >= t.Value
+	case "==":
+		c.Pass = v == t.Value
+	}
+	return c
+}
+
+// Thresholds holds the --threshold checks of a run
+type Thresholds struct {
+	list []Threshold
+}
+
+// RegisterThresholdFlags registers the repeatable --threshold flag on fs
+func RegisterThresholdFlags(fs *flag.FlagSet) *Thresholds {
+	t := &Thresholds{}
+	fs.Func("threshold", "pass/fail check on a summary metric, e.g. 'p99_ms<200' or 'error_rate_pct<=1' (repeatable; run exits 99 on failure)", func(s string) error {
+		th, err := ParseThreshold(s)
+		if err != nil {
+			return err
+		}
+		t.list = append(t.list, th)
+		return nil
+	})
+	return t
+}
+
+// Fields returns the thresholds for startup logs and results
+func (t *Thresholds) Fields() map[string]interface{} {
+	exprs := make([]string, len(t.list))
+	for i, th := range t.list {
+		exprs[i] = th.Expr
+	}
+	return map[string]interface{}{"thresholds": strings.Join(exprs, " ")}
+}
+
+// Check evaluates every threshold against s
+func (t *Thresholds) Check(s Summary) []CheckResult {
+	var out []CheckResult
+	for _, th := range t.list {
+		out = append(out, th.Check(s))
+	}
+	return out
+}
+
+// ChecksFailed reports whether any threshold check failed
+func (s Summary) ChecksFailed() bool {
+	for _, c := range s.Checks {
+		if !c.Pass {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks logs one line per threshold
+func (l *Logger) Checks(s Summary) {
+	if l.level < LogLevelMinimal {
+		return
+	}
+	for _, c := range s.Checks {
+		if c.Pass {
+			log.Printf("threshold | pass %s (%s = %s)", c.Name, c.Metric, formatTick(c.Actual, 0.001))
+		} else {
+			log.Printf("threshold | FAIL %s: %s", c.Name, c.Message())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Threshold
+		wantErr string
+	}{
+		{"p99_ms<200", Threshold{Expr: "p99_ms<200", Metric: "p99_ms", Op: "<", Value: 200}, ""},
+		{"error_rate_pct <= 1.5", Threshold{Expr: "error_rate_pct<=1.5", Metric: "error_rate_pct", Op: "<=", Value: 1.5}, ""},
+		{"rps>=1e3", Threshold{Expr: "rps>=1e3", Metric: "rps", Op: ">=", Value: 1000}, ""},
+		{"samples>0", Threshold{Expr: "samples>0", Metric: "samples", Op: ">", Value: 0}, ""},
+		{"timeout_pct==0", Threshold{Expr: "timeout_pct==0", Metric: "timeout_pct", Op: "==", Value: 0}, ""},
+		{"p99_ms", Threshold{}, "want e.g."},
+		{"<200", Threshold{}, "want e.g."},
+		{"p99_ms=200", Threshold{}, "unknown operator"},
+		{"p99_ms=<200", Threshold{}, "unknown operator"},
+		{"p42_ms<200", Threshold{}, "unknown metric"},
+		{"p99_ms<fast", Threshold{}, "invalid syntax"},
+		{"p99_ms<", Threshold{}, "invalid syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseThreshold(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseThreshold(%q) error = %v, want containing %q", tt.expr, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseThreshold(%q) error = %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseThreshold(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholdCheck(t *testing.T) {
+	run := Summary{Samples: 1000, OKRatePct: 99, RPS: 250, P99ms: 180}
+	tests := []struct {
+		expr      string
+		s         Summary
+		wantPass  bool
+		wantError bool
+		actual    float64
+	}{
+		{"p99_ms<200", run, true, false, 180},
+		{"p99_ms<180", run, false, false, 180},
+		{"p99_ms<=180", run, true, false, 180},
+		{"rps>250", run, false, false, 250},
+		{"rps>=250", run, true, false, 250},
+		{"error_rate_pct<=1", run, true, false, 1},
+		{"error_rate_pct<1", run, false, false, 1},
+		{"samples==1000", run, true, false, 1000},
+		{"p99_ms<200", Summary{}, false, true, 0},
+		{"error_rate_pct<1", Summary{}, false, true, 0},
+		{"server_cpu_us_per_req<100", run, false, true, 0},
+		{"capacity_knee_rps>100", run, false, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			th, err := ParseThreshold(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := th.Check(tt.s)
+			if c.Pass != tt.wantPass {
+				t.Errorf("Pass = %v, want %v (%s)", c.Pass, tt.wantPass, c.Message())
+			}
+			if (c.Error != "") != tt.wantError {
+				t.Errorf("Error = %q, want error %v", c.Error, tt.wantError)
+			}
+			if c.Actual != tt.actual {
+				t.Errorf("Actual = %v, want %v", c.Actual, tt.actual)
+			}
+		})
+	}
+}
+
+func TestChecksFailed(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []CheckResult
+		want   bool
+	}{
+		{"no thresholds", nil, false},
+		{"all pass", []CheckResult{{Pass: true}, {Pass: true}}, false},
+		{"one fails", []CheckResult{{Pass: true}, {Pass: false}}, true},
+		{"unmeasured fails", []CheckResult{{Error: "p99_ms not measured in this run"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Summary{Checks: tt.checks}).ChecksFailed(); got != tt.want {
+				t.Errorf("ChecksFailed() = %v, want %v", got, tt.want)
+			}
+			want := 0
+			if tt.want {
+				want = ThresholdExitCode
+			}
+			if got := ExitCode(Summary{Checks: tt.checks}); got != want {
+				t.Errorf("ExitCode() = %d, want %d", got, want)
+			}
+		})
+	}
+}
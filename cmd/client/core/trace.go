@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+)
+
+// NewEchoClient creates an echo client that propagates trace context and
+// records the server's x-request-id on the sample
+func NewEchoClient(httpClient *http.Client, addr string) echov1connect.EchoServiceClient {
+	return echov1connect.NewEchoServiceClient(httpClient, addr, connect.WithInterceptors(clientInterceptor()))
+}
+
+type requestIDKey struct{}
+
+// withRequestIDSink lets the client interceptor report the x-request-id
+// of the response back to DoRequest
+func withRequestIDSink(ctx context.Context, sink *atomic.Pointer[string]) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, sink)
+}
+
+// clientInterceptor wraps each attempt in a client span, injects
+// traceparent and captures x-request-id
+func clientInterceptor() connect.Interceptor {
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			ctx, span := tracing.Tracer().Start(ctx, req.Spec().Procedure,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attribute.String("rpc.system", "connect_rpc")))
+			defer span.End()
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header()))
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+			if id := resp.Header().Get("x-request-id"); id != "" {
+				span.SetAttributes(attribute.String("http.response.header.x-request-id", id))
+				if sink, ok := ctx.Value(requestIDKey{}).(*atomic.Pointer[string]); ok {
+					sink.Store(&id)
+				}
+			}
+			return resp, nil
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tuiLogLines   = 10  // Log lines shown under the dashboard
+	tuiLogReplay  = 500 // Log lines kept for replay when the dashboard closes
+	tuiTrendWidth = 60  // Seconds of history in the trend sparklines
+)
+
+// tui is a full-screen terminal dashboard. While it runs, the standard
+// logger is captured so log lines don't tear the screen; they are shown
+// in a panel and replayed to the original output on stop
+type tui struct {
+	out   io.Writer
+	logs  *tuiLog
+	title string
+
+	p99Trend []float64
+	rpsTrend []float64
+	errTrend []float64
+}
+
+func startTUI(out io.Writer) *tui {
+	logs := &tuiLog{orig: log.Writer()}
+	log.SetOutput(logs)
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l") // Alternate screen, hide cursor
+	return &tui{out: out, logs: logs, title: filepath.Base(os.Args[0])}
+}
+
+// stop restores the screen and replays the captured log lines
+func (t *tui) stop() {
+	fmt.Fprint(t.out, "\x1b[?25h\x1b[?1049l")
+	t.logs.release()
+}
+
+func (t *tui) render(s LiveStats) {
+	t.p99Trend = pushTrend(t.p99Trend, s.P99ms)
+	t.rpsTrend = pushTrend(t.rpsTrend, float64(s.RPS()))
+	t.errTrend = pushTrend(t.errTrend, s.ErrRatePct())
+
+	var b bytes.Buffer
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "\x1b[1m%s\x1b[0m  live  elapsed %s", t.title, s.Elapsed.Truncate(time.Second))
+	if s.Phase != "" {
+		fmt.Fprintf(&b, "  phase \x1b[1m%s\x1b[0m", s.Phase)
+	}
+	b.WriteString("\n\n")
+
+	fmt.Fprintf(&b, "  requests    ok %-10d err %-8d timeouts %d\n", s.OK, s.Err, s.Timeouts)
+	rate := fmt.Sprintf("%d rps", s.RPS())
+	if s.TargetRPS > 0 {
+		pct := 100 * float64(s.RPS()) / float64(s.TargetRPS)
+		rate = fmt.Sprintf("%d / %d rps (%.0f%%)", s.RPS(), s.TargetRPS, pct)
+		if pct < 90 {
+			rate = warn(rate)
+		}
+	}
+	fmt.Fprintf(&b, "  throughput  %s\n", rate)
+	errRate := fmt.Sprintf("%.2f%%", s.ErrRatePct())
+	if s.ErrRatePct() >= 1 {
+		errRate = warn(errRate)
+	}
+	fmt.Fprintf(&b, "  error rate  %s (last 1s)\n", errRate)
+	fmt.Fprintf(&b, "  in-flight   %-10d open conns %d\n", s.InFlight, s.OpenConns)
+	fmt.Fprintf(&b, "  latency     p50 %.2fms  p99 %.2fms  (last %v)\n\n", s.P50ms, s.P99ms, s.Window)
+
+	fmt.Fprintf(&b, "  p99   %s\n", sparkline(t.p99Trend))
+	fmt.Fprintf(&b, "  rps   %s\n", sparkline(t.rpsTrend))
+	fmt.Fprintf(&b, "  err%%  %s\n\n", sparkline(t.errTrend))
+
+	b.WriteString("\x1b[2m── log ──\x1b[0m\n")
+	for _, l := range t.logs.tail(tuiLogLines) {
+		b.WriteString("  " + l + "\n")
+	}
+	t.out.Write(b.Bytes())
+}
+
+func warn(s string) string {
+	return "\x1b[1;31m" + s + "\x1b[0m"
+}
+
+func pushTrend(tr []float64, v float64) []float64 {
+	tr = append(tr, v)
+	if len(tr) > tuiTrendWidth {
+		tr = tr[1:]
+	}
+	return tr
+}
+
+// sparkline scales values to block characters, relative to the max
+func sparkline(vs []float64) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	levels := []rune(blocks)
+	var max float64
+	for _, v := range vs {
+		if v > max {
+			max = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range vs {
+		i := 0
+		if max > 0 {
+			i = int(v / max * float64(len(levels)-1))
+		}
+		sb.WriteRune(levels[i])
+	}
+	if len(vs) > 0 {
+		fmt.Fprintf(&sb, "  %.2f (max %.2f)", vs[len(vs)-1], max)
+	}
+	return sb.String()
+}
+
+// tuiLog captures log output while the dashboard is on screen
+type tuiLog struct {
+	mu       sync.Mutex
+	orig     io.Writer
+	lines    []string
+	released bool
+}
+
+func (l *tuiLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		return l.orig.Write(p)
+	}
+	l.lines = append(l.lines, strings.TrimRight(string(p), "\n"))
+	if len(l.lines) > tuiLogReplay {
+		l.lines = l.lines[len(l.lines)-tuiLogReplay:]
+	}
+	return len(p), nil
+}
+
+func (l *tuiLog) tail(n int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.lines) <= n {
+		return append([]string(nil), l.lines...)
+	}
+	return append([]string(nil), l.lines[len(l.lines)-n:]...)
+}
+
+// release replays captured lines and passes further writes through
+func (l *tuiLog) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range l.lines {
+		fmt.Fprintln(l.orig, line)
+	}
+	l.lines = nil
+	l.released = true
+}
@@ -1,41 +1,116 @@
 package core
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // Record stores a single request sample
 type Record struct {
-	TsUnixNS  int64 // Timestamp in Unix nanoseconds
-	LatencyNS int64 // Latency in nanoseconds
-	OK        bool  // Request success status
+	TsUnixNS  int64  // Timestamp in Unix nanoseconds
+	LatencyNS int64  // Latency in nanoseconds
+	OK        bool   // Request success status
+	ErrClass  string // Error class for failed requests (see ClassifyError)
+	Timeout   bool   // Failed by timeout (request deadline, connect timeout, dead path)
+
+	// Retry/hedge outcome (see Policy)
+	Attempts       int   // Attempts made, 1 without retries
+	Hedges         int   // Duplicate requests sent
+	HedgeWon       bool  // Response came from a duplicate
+	FirstLatencyNS int64 // Latency of the first attempt
+
+	Warmup bool // Before the measurement window, excluded from headline stats
+
+	// Scenario phase (see Counters.SetPhase, WithPhase)
+	Phase      string // Phase label, empty for single-phase scenarios
+	Cycle      int    // Cycle index of the phase
+	PhaseFirst bool   // First request of the phase instance
+
+	Class string // Request class (see WithClass), empty when unclassified
+
+	TraceID   string // Trace ID when the request was sampled for tracing
+	RequestID string // Server x-request-id of the response
 }
 
 // Summary contains aggregated benchmark statistics
 type Summary struct {
-	Samples   int       // Total samples
-	OKRatePct float64   // Success rate percentage
-	RPS       float64   // Requests per second
-	DurationS float64   // Total duration in seconds
-	P50ms     float64   // 50th percentile latency
-	P90ms     float64   // 90th percentile latency
-	P95ms     float64   // 95th percentile latency
-	P99ms     float64   // 99th percentile latency
-	Meanms    float64   // Mean latency
-	Minms     float64   // Minimum latency
-	Maxms     float64   // Maximum latency
-	CDF_X_ms  []float64 // CDF X-axis (latency values)
-	CDF_Y     []float64 // CDF Y-axis (cumulative probability)
-	THR_Ts    []int64   // Throughput timestamps
-	THR_Val   []int     // Throughput values per second
+	Samples    int       // Total samples
+	OKRatePct  float64   // Success rate percentage
+	Timeouts   int       // Requests failed by timeout
+	TimeoutPct float64   // Timeout rate percentage
+	RPS        float64   // Requests per second
+	DurationS  float64   // Total duration in seconds
+	P50ms      float64   // 50th percentile latency
+	P90ms      float64   // 90th percentile latency
+	P95ms      float64   // 95th percentile latency
+	P99ms      float64   // 99th percentile latency
+	Meanms     float64   // Mean latency
+	Minms      float64   // Minimum latency
+	Maxms      float64   // Maximum latency
+	CDF_X_ms   []float64 // CDF X-axis (latency values)
+	CDF_Y      []float64 // CDF Y-axis (cumulative probability)
+	THR_Ts     []int64   // Throughput timestamps
+	THR_Val    []int     // Throughput values per second
+
+	Series          []SeriesPoint // Latency percentiles per interval
+	SeriesIntervalS float64       // Series interval in seconds
+
+	ErrorClasses map[string]int // Failed requests per error class
+
+	Resilience Resilience     // Retry/hedge outcome
+	Phases     []PhaseSummary // Per-phase breakdown, in order of appearance
+	Classes    []ClassSummary // Per-request-class breakdown
+
+	Resources  Resources         // Client resource usage during the run
+	Server     ServerCost        // Server cost per request (needs --server-admin)
+	Headers    HeaderStats       // Request header compression (header-bloat)
+	TableSweep []TableSweepPoint // Per header table size (header-bloat --table-sweep)
+	Capacity   CapacityResult    // Capacity search (high-traffic --capacity)
+	Checks     []CheckResult     // Threshold outcomes (--threshold)
+
+	Window          string // Measurement window used for headline stats
+	WindowFallback  bool   // Window held no samples, headline stats use the full run (or step)
+	ExcludedSamples int    // Warm-up samples excluded from headline stats
+
+	Meta map[string]string // Run configuration (scenario + transport tuning)
+}
+
+// Resilience summarizes retry and hedging behaviour of a run
+type Resilience struct {
+	FirstP50ms      float64 // First-attempt latency percentiles
+	FirstP90ms      float64
+	FirstP99ms      float64
+	AttemptsMean    float64 // Attempts per request
+	RetriedPct      float64 // Requests that needed more than one attempt
+	HedgedPct       float64 // Requests that sent a duplicate
+	HedgeWinRatePct float64 // Hedged requests answered by the duplicate
+}
+
+// Active reports whether any request was retried or hedged
+func (r Resilience) Active() bool {
+	return r.RetriedPct > 0 || r.HedgedPct > 0
+}
+
+// Result is the machine-readable output of a single run
+type Result struct {
+	Label   string
+	Summary Summary
 }
 
 // Counters holds atomic counters for tracking request stats
 type Counters struct {
-	TotalOK     atomic.Uint64
-	TotalErr    atomic.Uint64
-	ErrLogCount atomic.Int64
+	TotalOK      atomic.Uint64
+	TotalErr     atomic.Uint64
+	TotalTimeout atomic.Uint64
+	ErrLogCount  atomic.Int64
+	InFlight     atomic.Int64 // Requests currently in flight
+
+	phase      atomic.Pointer[phaseTag] // Run-wide phase, see SetPhase
+	targetRPS  atomic.Int64             // Dispatcher rate target, see SetTargetRPS
+	live       liveLatency              // Latencies since the last progress tick
+	hist       latencyHistogram         // Exported on the metrics endpoint
+	errClasses sync.Map                 // Error class -> *atomic.Uint64
 }
 
 // NewCounters creates a new Counters instance
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Steady-state detection: windowed p90 must stay within tolerance
+// of its mean for steadyWindows consecutive windows
+const (
+	steadyWindowSize       = 1 * time.Second
+	steadyWindows          = 5
+	steadyMinWindowSamples = 10
+)
+
+// Window selects which samples count towards the headline stats.
+// Excluded samples stay in the raw output, flagged as warmup
+type Window struct {
+	Warmup          time.Duration // Fixed warm-up excluded from the start of the run
+	SteadyState     bool          // Detect steady state from windowed percentile stability
+	SteadyTolerance float64       // Max relative p90 deviation between windows
+	SeriesInterval  time.Duration // Bucket width of the latency time series
+}
+
+// RegisterWindowFlags registers warm-up and steady-state flags on fs
+func RegisterWindowFlags(fs *flag.FlagSet) *Window {
+	w := &Window{}
+	fs.DurationVar(&w.Warmup, "warmup", 0, "exclude samples from the first part of the run from headline stats (still written to CSV)")
+	fs.BoolVar(&w.SteadyState, "steady-state", false, "exclude samples before p90 latency stabilizes (1s windows), after --warmup")
+	fs.Float64Var(&w.SteadyTolerance, "steady-tolerance", 0.2, "max relative p90 deviation across windows for --steady-state")
+	fs.DurationVar(&w.SeriesInterval, "series-interval", defaultSeriesInterval, "interval for per-window latency percentiles (JSON, series CSV, HTML chart)")
+	return w
+}
+
+// Fields returns the window settings for startup logs and results
+func (w *Window) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"warmup":           w.Warmup,
+		"steady_state":     w.SteadyState,
+		"steady_tolerance": w.SteadyTolerance,
+		"series_interval":  w.SeriesInterval,
+	}
+}
+
+// Cutoff is a resolved measurement window: samples started before StartNS
+// are warm-up
+type Cutoff struct {
+	StartNS  int64
+	Desc     string
+	Interval time.Duration // Time-series interval
+}
+
+// Summarize is Resolve followed by Cutoff.Summarize on the same records,
+// so it sets Record.Warmup on all
+func (w *Window) Summarize(all []Record) Summary {
+	return w.Resolve(all).Summarize(all)
+}
+
+// Resolve computes the measurement window of a run. Scenarios that
+// summarize subsets resolve once on all records and reuse the Cutoff
+func (w *Window) Resolve(all []Record) Cutoff {
+	if len(all) == 0 {
+		return Cutoff{Desc: "full run", Interval: w.SeriesInterval}
+	}
+	runStart := int64(math.MaxInt64)
+	for _, r := range all {
+		if r.TsUnixNS < runStart {
+			runStart = r.TsUnixNS
+		}
+	}
+
+	c := Cutoff{StartNS: runStart, Desc: "full run", Interval: w.SeriesInterval}
+	if w.Warmup > 0 {
+		c.StartNS = runStart + w.Warmup.Nanoseconds()
+		c.Desc = fmt.Sprintf("after %v warmup", w.Warmup)
+	}
+	if w.SteadyState {
+		if start, ok := detectSteadyState(all, c.StartNS, w.SteadyTolerance); ok {
+			c.StartNS = start
+			c.Desc = fmt.Sprintf("steady state from +%.1fs (p90 within %.0f%% over %dx%v windows)",
+				float64(start-runStart)/1e9, 100*w.SteadyTolerance, steadyWindows, steadyWindowSize)
+		} else {
+			c.Desc += ", steady state not detected"
+		}
+	}
+	return c
+}
+
+// Summarize summarizes the records inside the window. It sets Record.Warmup
+// on the caller's records, so the CSV written afterwards marks the excluded
+// samples; pass a copy to keep them untouched. The throughput and latency
+// series keep the warm-up so it stays visible in charts
+func (c Cutoff) Summarize(rs []Record) Summary {
+	for i := range rs {
+		rs[i].Warmup = rs[i].TsUnixNS < c.StartNS
+	}
+	return summarizeWindow(rs, c.Desc, c.Interval)
+}
+
+// SummarizeSteps resolves the window separately for each step of a stepped
+// run, so every step drops its own warm-up rather than only the first step.
+// step names the step of a record; records are flagged in place
+func (w *Window) SummarizeSteps(all []Record, step func(Record) string) Summary {
+	var order []string
+	steps := make(map[string][]Record)
+	for _, r := range all {
+		k := step(r)
+		if _, ok := steps[k]; !ok {
+			order = append(order, k)
+		}
+		steps[k] = append(steps[k], r)
+	}
+
+	cutoffs := make(map[string]Cutoff, len(steps))
+	descs := make([]string, 0, len(order))
+	fallback := false
+	for _, k := range order {
+		c := w.Resolve(steps[k])
+		included := 0
+		for _, r := range steps[k] {
+			if r.TsUnixNS >= c.StartNS {
+				included++
+			}
+		}
+		if included == 0 {
+			// Window longer than the step: keep the whole step
+			c.StartNS = math.MinInt64
+			c.Desc += ", window empty: full step used"
+			fallback = true
+		}
+		cutoffs[k] = c
+		descs = append(descs, k+" "+c.Desc)
+	}
+	for i := range all {
+		all[i].Warmup = all[i].TsUnixNS < cutoffs[step(all[i])].StartNS
+	}
+	s := summarizeWindow(all, "per step: "+strings.Join(descs, "; "), w.SeriesInterval)
+	s.WindowFallback = s.WindowFallback || fallback
+	return s
+}
+
+// summarizeWindow summarizes the records not flagged as warm-up
+func summarizeWindow(rs []Record, desc string, interval time.Duration) Summary {
+	included := make([]Record, 0, len(rs))
+	for _, r := range rs {
+		if !r.Warmup {
+			included = append(included, r)
+		}
+	}
+	fallback := len(included) == 0 && len(rs) > 0
+	if fallback {
+		// Window longer than the run: fall back to every sample, and
+		// unflag them so the CSV matches the stats
+		for i := range rs {
+			rs[i].Warmup = false
+		}
+		included = rs
+		desc += ", window empty: full run used"
+	}
+
+	s := Summarize(included)
+	s.THR_Ts, s.THR_Val = throughput(rs)
+	if interval <= 0 {
+		interval = defaultSeriesInterval
+	}
+	s.Series = timeSeries(rs, interval)
+	s.SeriesIntervalS = interval.Seconds()
+	s.Window = desc
+	s.WindowFallback = fallback
+	s.ExcludedSamples = len(rs) - len(included)
+	return s
+}
+
+// detectSteadyState returns the start of the first run of steadyWindows
+// consecutive windows at or after from whose p90 stays within tol of their mean.
+// A sparse window breaks the run: the windows must be contiguous in time
+func detectSteadyState(all []Record, from int64, tol float64) (int64, bool) {
+	size := steadyWindowSize.Nanoseconds()
+	buckets := make(map[int64][]float64)
+	var maxIdx int64 = -1
+	for _, r := range all {
+		if r.TsUnixNS < from {
+			continue
+		}
+		idx := (r.TsUnixNS - from) / size
+		buckets[idx] = append(buckets[idx], float64(r.LatencyNS))
+		if idx > maxIdx {
+			maxIdx = idx
+		}
+	}
+
+	// p90 per window, skipping windows with too few samples
+	type window struct {
+		idx int64
+		p90 float64
+	}
+	var wins []window
+	for i := int64(0); i <= maxIdx; i++ {
+		b := buckets[i]
+		if len(b) < steadyMinWindowSamples {
+			continue
+		}
+		sort.Float64s(b)
+		wins = append(wins, window{idx: i, p90: Percentile(b, 0.90)})
+	}
+
+	for i := 0; i+steadyWindows <= len(wins); i++ {
+		if wins[i+steadyWindows-1].idx-wins[i].idx != steadyWindows-1 {
+			continue
+		}
+		var mean float64
+		for _, w := range wins[i : i+steadyWindows] {
+			mean += w.p90
+		}
+		mean /= steadyWindows
+		stable := true
+		for _, w := range wins[i : i+steadyWindows] {
+			if math.Abs(w.p90-mean) > tol*mean {
+				stable = false
+				break
+			}
+		}
+		if stable {
+			return from + wins[i].idx*size, true
+		}
+	}
+	return 0, false
+}
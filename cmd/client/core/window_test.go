@@ -0,0 +1,137 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// windowRecords builds steadyMinWindowSamples records per window, one latency
+// per window (0 = sparse window with a single sample)
+func windowRecords(from int64, lat []time.Duration) []Record {
+	var rs []Record
+	size := steadyWindowSize.Nanoseconds()
+	for w, l := range lat {
+		n := steadyMinWindowSamples
+		if l == 0 {
+			n, l = 1, time.Millisecond
+		}
+		for i := 0; i < n; i++ {
+			rs = append(rs, Record{
+				TsUnixNS:  from + int64(w)*size + int64(i)*size/int64(n),
+				LatencyNS: l.Nanoseconds(),
+				OK:        true,
+			})
+		}
+	}
+	return rs
+}
+
+func TestDetectSteadyState(t *testing.T) {
+	const from = int64(1_700_000_000) * 1e9
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		lat     []time.Duration
+		wantOK  bool
+		wantWin int64 // First steady window
+	}{
+		{"stable from start", []time.Duration{10 * ms, 10 * ms, 10 * ms, 10 * ms, 10 * ms}, true, 0},
+		{"warm-up then stable", []time.Duration{50 * ms, 30 * ms, 10 * ms, 10 * ms, 11 * ms, 10 * ms, 9 * ms}, true, 2},
+		{"never stable", []time.Duration{10 * ms, 40 * ms, 10 * ms, 40 * ms, 10 * ms, 40 * ms}, false, 0},
+		{"too few windows", []time.Duration{10 * ms, 10 * ms, 10 * ms, 10 * ms}, false, 0},
+		{"sparse window breaks the run", []time.Duration{10 * ms, 10 * ms, 0, 10 * ms, 10 * ms, 10 * ms}, false, 0},
+		{"stable after sparse window", []time.Duration{10 * ms, 0, 10 * ms, 10 * ms, 10 * ms, 10 * ms, 10 * ms}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := detectSteadyState(windowRecords(from, tt.lat), from, 0.2)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if want := from + tt.wantWin*steadyWindowSize.Nanoseconds(); ok && got != want {
+				t.Errorf("start = window %d, want window %d", (got-from)/steadyWindowSize.Nanoseconds(), tt.wantWin)
+			}
+		})
+	}
+}
+
+func TestDetectSteadyStateSkipsBeforeFrom(t *testing.T) {
+	const start = int64(1_700_000_000) * 1e9
+	ms := time.Millisecond
+	rs := windowRecords(start, []time.Duration{10 * ms, 10 * ms, 10 * ms, 10 * ms, 10 * ms, 10 * ms, 10 * ms})
+	from := start + 2*steadyWindowSize.Nanoseconds()
+	got, ok := detectSteadyState(rs, from, 0.2)
+	if !ok || got != from {
+		t.Errorf("detectSteadyState = %d, %v, want %d, true", got, ok, from)
+	}
+}
+
+func TestSummarizeStepsPerStepWarmup(t *testing.T) {
+	const start = int64(1_700_000_000) * 1e9
+	ms := time.Millisecond
+	lat := []time.Duration{10 * ms, 10 * ms, 10 * ms, 10 * ms}
+	step := func(class string, from int64) []Record {
+		rs := windowRecords(from, lat)
+		for i := range rs {
+			rs[i].Class = class
+		}
+		return rs
+	}
+	// Two 4s steps back to back, and a 1s step shorter than the warm-up
+	all := append(step("a", start), step("b", start+4*steadyWindowSize.Nanoseconds())...)
+	all = append(all, windowRecords(start+8*steadyWindowSize.Nanoseconds(), lat[:1])...)
+	for i := range all[80:] {
+		all[80+i].Class = "c"
+	}
+
+	w := &Window{Warmup: 2 * time.Second}
+	s := w.SummarizeSteps(all, func(r Record) string { return r.Class })
+
+	// a and b each drop their first 2s; c keeps its only second
+	if want := 2 * 2 * steadyMinWindowSamples; s.ExcludedSamples != want {
+		t.Errorf("ExcludedSamples = %d, want %d", s.ExcludedSamples, want)
+	}
+	for _, class := range []string{"a", "b"} {
+		if got := s.Class(class).Samples; got != 2*steadyMinWindowSamples {
+			t.Errorf("class %s samples = %d, want %d", class, got, 2*steadyMinWindowSamples)
+		}
+	}
+	if got := s.Class("c").Samples; got != steadyMinWindowSamples {
+		t.Errorf("class c samples = %d, want %d", got, steadyMinWindowSamples)
+	}
+	want := "per step: a after 2s warmup; b after 2s warmup; c after 2s warmup, window empty: full step used"
+	if s.Window != want || !s.WindowFallback {
+		t.Errorf("Window = %q, WindowFallback = %v; want %q, true", s.Window, s.WindowFallback, want)
+	}
+	// Flags are written back to the caller's records for the CSV
+	if !all[0].Warmup || all[len(all)-1].Warmup {
+		t.Error("warm-up flags not set on the records")
+	}
+}
+
+func TestCutoffSummarizeFallback(t *testing.T) {
+	const start = int64(1_700_000_000) * 1e9
+	rs := windowRecords(start, []time.Duration{time.Millisecond, time.Millisecond})
+
+	// A window starting after the last sample
+	c := Cutoff{StartNS: start + 10*steadyWindowSize.Nanoseconds(), Desc: "after 10s warmup"}
+	s := c.Summarize(rs)
+	if !s.WindowFallback || s.Samples != len(rs) || s.ExcludedSamples != 0 {
+		t.Errorf("WindowFallback = %v, Samples = %d, ExcludedSamples = %d; want true, %d, 0", s.WindowFallback, s.Samples, s.ExcludedSamples, len(rs))
+	}
+	if want := "after 10s warmup, window empty: full run used"; s.Window != want {
+		t.Errorf("Window = %q, want %q", s.Window, want)
+	}
+	for _, r := range rs {
+		if r.Warmup {
+			t.Fatal("records used for the stats are still flagged as warm-up")
+		}
+	}
+
+	// A window inside the run flags the excluded records in place
+	c.StartNS = start + steadyWindowSize.Nanoseconds()
+	s = c.Summarize(rs)
+	if s.WindowFallback || s.ExcludedSamples != steadyMinWindowSamples || !rs[0].Warmup {
+		t.Errorf("WindowFallback = %v, ExcludedSamples = %d, rs[0].Warmup = %v; want false, %d, true", s.WindowFallback, s.ExcludedSamples, rs[0].Warmup, steadyMinWindowSamples)
+	}
+}
@@ -6,14 +6,34 @@ import (
 	"time"
 
 	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	echov1 "h3-vs-h2-k6/echo/v1"
 	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
 )
 
 // RequestFunc is a function that builds and executes a request
 // Returns the response size and any error
 type RequestFunc func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error)
 
+type requestTimeoutKey struct{}
+
+// WithRequestTimeout returns a context under which DoRequest bounds every
+// request by d. The benchmark-wide context stays the parent, so stopping
+// the run still cancels in-flight requests (0 = no per-request deadline)
+func WithRequestTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, d)
+}
+
+// RequestTimeout returns the per-request deadline carried by ctx
+func RequestTimeout(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(requestTimeoutKey{}).(time.Duration)
+	return d
+}
+
 // DoRequest executes a single request with logging and metrics collection
 func DoRequest(
 	ctx context.Context,
@@ -24,17 +44,63 @@ func DoRequest(
 	reqID int64,
 	requestFn RequestFunc,
 ) {
+	reqCtx := ctx
+	if d := RequestTimeout(ctx); d > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	var phase string
+	var cycle int
+	var phaseFirst bool
+	if tag := currentPhase(ctx, counters); tag != nil {
+		phase, cycle, phaseFirst = tag.name, tag.cycle, tag.claim()
+	}
+
+	// Request span is the parent of one client span per attempt
+	reqCtx, span := tracing.Tracer().Start(reqCtx, "bench.request", trace.WithAttributes(
+		attribute.Int64("bench.request_id", reqID),
+		attribute.String("bench.phase", phase),
+		attribute.String("bench.class", classFrom(ctx)),
+	))
+	var serverReqID atomic.Pointer[string]
+	reqCtx = withRequestIDSink(reqCtx, &serverReqID)
+
+	st := &attemptStats{}
+	counters.InFlight.Add(1)
 	t0 := time.Now()
-	respSize, err := requestFn(ctx, cl, reqID)
+	respSize, err := requestFn(withAttemptStats(reqCtx, st), cl, reqID)
 	lat := time.Since(t0)
+	counters.InFlight.Add(-1)
+
+	// Without retry/hedge wrappers the request is its own first attempt
+	attempts := int(st.attempts.Load())
+	if attempts == 0 {
+		attempts = 1
+	}
+	firstNS := st.firstNS.Load()
+	if firstNS == 0 {
+		firstNS = lat.Nanoseconds()
+	}
 
 	ok := err == nil
-	if ok {
-		counters.TotalOK.Add(1)
-	} else {
-		counters.TotalErr.Add(1)
+	errClass, timedOut := ClassifyRequestError(reqCtx, err)
+	if !ok {
+		span.SetStatus(codes.Error, errClass)
+	}
+	span.End()
+	var traceID, requestID string
+	if sc := span.SpanContext(); sc.IsSampled() {
+		traceID = sc.TraceID().String()
+	}
+	if id := serverReqID.Load(); id != nil {
+		requestID = *id
+	}
+	counters.Observe(lat, ok, errClass, timedOut)
+	if !ok {
 		errCount := counters.ErrLogCount.Add(1)
-		logger.ErrorThrottled(errCount, err, 10, 1000)
+		logger.ErrorThrottled(errCount, errClass, err, 10, 1000)
 	}
 
 	// Log request completion
@@ -42,7 +108,23 @@ func DoRequest(
 
 	// Send record to collector
 	select {
-	case latCh <- Record{TsUnixNS: t0.UnixNano(), LatencyNS: lat.Nanoseconds(), OK: ok}:
+	case latCh <- Record{
+		TsUnixNS:       t0.UnixNano(),
+		LatencyNS:      lat.Nanoseconds(),
+		OK:             ok,
+		ErrClass:       errClass,
+		Timeout:        timedOut,
+		Attempts:       attempts,
+		Hedges:         int(st.hedges.Load()),
+		HedgeWon:       st.hedgeWon.Load(),
+		FirstLatencyNS: firstNS,
+		Phase:          phase,
+		Cycle:          cycle,
+		PhaseFirst:     phaseFirst,
+		Class:          classFrom(ctx),
+		TraceID:        traceID,
+		RequestID:      requestID,
+	}:
 	default:
 		// Drop if channel is full
 	}
@@ -65,6 +147,12 @@ func SimpleRequest(payload int) RequestFunc {
 
 // HeaderBloatRequest creates a request with multiple bloated headers
 func HeaderBloatRequest(payload int, headerSize int, headerPairs int) RequestFunc {
+	return HeaderBloatValuesRequest(payload, headerSize, headerPairs, HeaderValuesStatic)
+}
+
+// HeaderBloatValuesRequest creates a request with multiple bloated headers
+// whose values change between requests as selected by values
+func HeaderBloatValuesRequest(payload int, headerSize int, headerPairs int, values HeaderValues) RequestFunc {
 	// Pre-generate header values
 	headerValues := make([]string, headerPairs)
 	sizePerHeader := headerSize / headerPairs
@@ -83,7 +171,7 @@ func HeaderBloatRequest(payload int, headerSize int, headerPairs int) RequestFun
 
 		// Add bloated headers
 		for i, val := range headerValues {
-			req.Header().Set(headerKey(i), val)
+			req.Header().Set(headerKey(i), values.headerValue(val, i, reqID))
 		}
 
 		resp, err := cl.Unary(ctx, req)
@@ -114,34 +202,15 @@ func RequestWithHeaders(payload int, headers map[string]string) RequestFunc {
 	}
 }
 
-// ProgressPrinter prints progress every second
-func ProgressPrinter(ctx context.Context, counters *Counters, logger *Logger) {
-	tk := time.NewTicker(1 * time.Second)
-	defer tk.Stop()
-	var lastOK, lastErr uint64
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-tk.C:
-			o := counters.TotalOK.Load()
-			e := counters.TotalErr.Load()
-			dOK := o - lastOK
-			dErr := e - lastErr
-			lastOK, lastErr = o, e
-			logger.Progress(o, e, dOK, dErr)
-		}
-	}
-}
-
 // Dispatcher sends job tokens at constant RPS
-func Dispatcher(ctx context.Context, jobs chan<- struct{}, rps int, logger *Logger) {
+func Dispatcher(ctx context.Context, jobs chan<- struct{}, rps int, counters *Counters, logger *Logger) {
 	if rps <= 0 {
 		rps = 1000
 	}
 	interval := time.Second / time.Duration(rps)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	counters.SetTargetRPS(rps)
 
 	logger.Info("Dispatcher started: target RPS=%d, interval=%v", rps, interval)
 
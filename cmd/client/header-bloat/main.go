@@ -13,7 +13,8 @@ import (
 	"time"
 
 	"h3-vs-h2-k6/cmd/client/core"
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -23,6 +24,11 @@ import (
 // dengan large metadata overhead
 //
 // Config: 1000 clients, 2000 RPS, 8KB headers (32 pairs), 120s
+// Variasi nilai header (--header-values): static, varying, random
+// Ukuran header block request HPACK/QPACK yang ter-encode (estimasi sisi
+// client, header response tidak diukur) dilaporkan di akhir run
+// Sweep ukuran tabel HPACK (--table-sweep): satu step per ukuran tabel,
+// --warmup/--steady-state diterapkan per step
 // =====================================
 
 const (
@@ -35,6 +41,10 @@ const (
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -42,13 +52,47 @@ func main() {
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "Header Bloat Benchmark", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Header Bloat Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
+
+		// Header value variant
+		headerValues = flag.String("header-values", "static", "header values between requests: static (same every request), varying (every 4th header per-request), random (fresh every request)")
+
+		// HPACK table size sweep
+		tableSweep = flag.String("table-sweep", "", "comma-separated HTTP/2 HPACK encoder table sizes in bytes to run one step each, e.g. 1,1024,4096,16384,65536 (1 disables indexing; start the server with --h2-decoder-table-size >= the largest)")
+		sweepStep  = flag.Duration("table-sweep-step", 20*time.Second, "duration of each --table-sweep step")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedClients, Payload: fixedPayload, RPS: fixedRPS, Duration: fixedDur})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	values, err := core.ParseHeaderValues(*headerValues)
+	if err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+	sweepSizes, err := core.ParseTableSizes(*tableSweep)
+	if err != nil {
+		log.Printf("table-sweep: %v", err)
+		return 1
+	}
 
 	// ---- Setup Logger ----
 	logLevel := core.LogLevelNormal
@@ -60,78 +104,84 @@ func main() {
 	}
 	logger := core.NewLogger(logLevel)
 
+	// Steps: a single run, or one per HPACK table size with --table-sweep
+	tableSizes := []int{tcfg.H2EncoderTableSize}
+	stepDur := load.Duration
+	sweeping := len(sweepSizes) > 0
+	if sweeping {
+		tableSizes, stepDur = sweepSizes, *sweepStep
+		if *useH3 {
+			// QPACK in quic-go has no dynamic table, one step is the HTTP/3 baseline
+			tableSizes = []int{transport.QPACKTableCapacity}
+		}
+	}
+
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("header-bloat", map[string]interface{}{
-		"pid":          os.Getpid(),
-		"cwd":          cwd,
-		"addr":         *addr,
-		"protocol":     core.ProtocolName(*useH3),
-		"insecure":     *insecure,
-		"clients":      fixedClients,
-		"payload":      fixedPayload,
-		"duration":     fixedDur,
-		"rps":          fixedRPS,
-		"header-size":  fixedHeaderSize,
-		"header-pairs": fixedHeaderPairs,
-	})
+	config := map[string]interface{}{
+		"scenario":         "header-bloat",
+		"pid":              os.Getpid(),
+		"cwd":              cwd,
+		"addr":             *addr,
+		"protocol":         core.ProtocolName(*useH3),
+		"insecure":         *insecure,
+		"clients":          load.Workers,
+		"payload":          load.Payload,
+		"duration":         stepDur * time.Duration(len(tableSizes)),
+		"rps":              load.RPS,
+		"header-size":      fixedHeaderSize,
+		"header-pairs":     fixedHeaderPairs,
+		"header-values":    values,
+		"table-sweep":      *tableSweep,
+		"table-sweep-step": *sweepStep,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("header-bloat", config)
 
-	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	shutdownTracing, err := trc.Setup("bench-client-header-bloat")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
-	// Build HTTP client
-	httpClient, closer := core.NewHTTPClient(*useH3, *insecure, logger)
-	defer closer()
-	client := echov1connect.NewEchoServiceClient(httpClient, *addr)
+	// Absolutkan output path
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
-
-	// Timer untuk durasi test
-	go func() {
-		select {
-		case <-time.After(fixedDur):
-			logger.Info("Duration elapsed: %v -> stopping", fixedDur)
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Channels & counters
 	latCh := make(chan core.Record, 1<<20)
-	jobs := make(chan struct{}, 1<<16)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "header-bloat", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
 	var reqCounter atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
-	}
-
-	// Create request function with header bloat
-	requestFn := core.HeaderBloatRequest(fixedPayload, fixedHeaderSize, fixedHeaderPairs)
-
-	// Start workers
-	var wg sync.WaitGroup
-	wg.Add(fixedClients)
-	for i := 0; i < fixedClients; i++ {
-		go func(workerID int) {
-			defer wg.Done()
-			logger.Debug("Worker %d started", workerID)
-			core.JobWorker(ctx, client, latCh, counters, logger, jobs, requestFn, &reqCounter)
-			logger.Debug("Worker %d stopped", workerID)
-		}(i)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
-	// Start dispatcher (constant RPS)
-	var dispWg sync.WaitGroup
-	dispWg.Add(1)
-	go func() {
-		defer dispWg.Done()
-		core.Dispatcher(ctx, jobs, fixedRPS, logger)
-	}()
-
 	// Collector goroutine
 	var all []core.Record
 	var mu sync.Mutex
@@ -145,53 +195,136 @@ func main() {
 		}
 	}()
 
-	// Wait for completion
+	// runStep drives constant-RPS load with a fresh client until stepCtx ends
+	runStep := func(stepCtx context.Context, cfg *transport.Config, runID string) (core.HeaderStats, core.ServerCost) {
+		httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, cfg, logger)
+		defer closer()
+		client := core.NewEchoClient(httpClient, *addr)
+		srvStats.StartRun(runID, logger)
+
+		// Create request function with header bloat, measuring HPACK/QPACK block sizes
+		headers := core.NewHeaderCompression(*useH3, cfg)
+		requestFn := policy.Wrap(headers.Wrap(core.HeaderBloatValuesRequest(load.Payload, fixedHeaderSize, fixedHeaderPairs, values)))
+
+		// Start workers
+		jobs := make(chan struct{}, 1<<16)
+		var wg sync.WaitGroup
+		wg.Add(load.Workers)
+		for i := 0; i < load.Workers; i++ {
+			go func(workerID int) {
+				defer wg.Done()
+				logger.Debug("Worker %d started", workerID)
+				core.JobWorker(stepCtx, client, latCh, counters, logger, jobs, requestFn, &reqCounter)
+				logger.Debug("Worker %d stopped", workerID)
+			}(i)
+		}
+
+		// Dispatcher (constant RPS) runs until the step ends
+		core.Dispatcher(stepCtx, jobs, load.RPS, counters, logger)
+		wg.Wait()
+		return headers.Stats(), srvStats.EndRun(logger)
+	}
+
+	// Run each step: one for a plain run, one per table size when sweeping
 	start := time.Now()
-	dispWg.Wait()
+	type stepResult struct {
+		size    int
+		headers core.HeaderStats
+		server  core.ServerCost
+	}
+	var results []stepResult
+	for _, size := range tableSizes {
+		if ctx.Err() != nil {
+			break
+		}
+		stepCfg := *tcfg
+		stepCfg.H2EncoderTableSize = size
+		// Cancel rather than time out, so in-flight requests cut off by the
+		// end of a step are not counted as timeouts
+		stepCtx, stepCancel := context.WithCancel(ctx)
+		timer := time.AfterFunc(stepDur, func() {
+			logger.Info("Duration elapsed: %v -> stopping", stepDur)
+			stepCancel()
+		})
+		runID := tcfg.RunID
+		if sweeping {
+			stepCtx = core.WithClass(stepCtx, core.TableSizeClass(size))
+			runID = fmt.Sprintf("%s-table%d", tcfg.RunID, size)
+			logger.Info("Table sweep step: h2_encoder_table_size=%d for %v", size, stepDur)
+		}
+		headers, server := runStep(stepCtx, &stepCfg, runID)
+		timer.Stop()
+		stepCancel()
+		results = append(results, stepResult{size: size, headers: headers, server: server})
+	}
 	cancel()
-	wg.Wait()
+	progress.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	var sum core.Summary
+	if sweeping {
+		// Each table size starts on a fresh client, so each step has its own warm-up
+		sum = window.SummarizeSteps(all, func(r core.Record) string { return r.Class })
+	} else {
+		sum = window.Summarize(all)
+	}
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	if sweeping {
+		for _, r := range results {
+			c := sum.Class(core.TableSizeClass(r.size))
+			sum.TableSweep = append(sum.TableSweep, core.NewTableSweepPoint(r.size, c, r.headers, r.server))
+		}
+	} else if len(results) > 0 {
+		sum.Server = results[0].server
+		sum.Headers = results[0].headers
+	}
 	mu.Unlock()
 
 	// Print results
 	fmt.Printf("\n")
 	logger.Summary(map[string]interface{}{
-		"scenario":     "header_bloat",
-		"protocol":     core.ProtocolName(*useH3),
-		"header_size":  fixedHeaderSize,
-		"header_pairs": fixedHeaderPairs,
-		"samples":      sum.Samples,
-		"ok_rate_%":    fmt.Sprintf("%.2f", sum.OKRatePct),
-		"rps":          fmt.Sprintf("%.2f", sum.RPS),
-		"p50_ms":       fmt.Sprintf("%.6f", sum.P50ms),
-		"p90_ms":       fmt.Sprintf("%.6f", sum.P90ms),
-		"p95_ms":       fmt.Sprintf("%.6f", sum.P95ms),
-		"p99_ms":       fmt.Sprintf("%.6f", sum.P99ms),
-		"mean_ms":      fmt.Sprintf("%.6f", sum.Meanms),
-		"min_ms":       fmt.Sprintf("%.6f", sum.Minms),
-		"max_ms":       fmt.Sprintf("%.6f", sum.Maxms),
+		"scenario":                 "header_bloat",
+		"protocol":                 core.ProtocolName(*useH3),
+		"header_size":              fixedHeaderSize,
+		"header_pairs":             fixedHeaderPairs,
+		"header_values":            values,
+		"header_codec":             sum.Headers.Codec,
+		"est_header_ratio":         fmt.Sprintf("%.3f", sum.Headers.Ratio),
+		"est_header_bytes_per_req": fmt.Sprintf("%.0f", sum.Headers.MeanBlockBytes),
+		"samples":                  sum.Samples,
+		"ok_rate_%":                fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":                 sum.Timeouts,
+		"rps":                      fmt.Sprintf("%.2f", sum.RPS),
+		"p50_ms":                   fmt.Sprintf("%.6f", sum.P50ms),
+		"p90_ms":                   fmt.Sprintf("%.6f", sum.P90ms),
+		"p95_ms":                   fmt.Sprintf("%.6f", sum.P95ms),
+		"p99_ms":                   fmt.Sprintf("%.6f", sum.P99ms),
+		"mean_ms":                  fmt.Sprintf("%.6f", sum.Meanms),
+		"min_ms":                   fmt.Sprintf("%.6f", sum.Minms),
+		"max_ms":                   fmt.Sprintf("%.6f", sum.Maxms),
 	})
 
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.HeaderCompression(sum)
+	logger.TableSweep(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
 
 	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
@@ -13,7 +13,8 @@ import (
 	"time"
 
 	"h3-vs-h2-k6/cmd/client/core"
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -30,6 +31,14 @@ import (
 // FIXED CONFIGURATION:
 // Config: 1000 workers, 60s ramp-up to 15K RPS, 120s sustained, 60s ramp-down, 512B payload
 // Total duration: 240s
+//
+// Capacity mode (--capacity):
+// - Offered load dinaikkan 2x per step (mulai --capacity-start) sampai SLO
+//   (p99, error rate, throughput) gagal, lalu dicari dengan binary search
+//   antara step terakhir yang lolos dan step pertama yang gagal
+// - Setiap step harus memenuhi SLO selama --capacity-hold
+// - Hasil: knee (RPS tertinggi yang lolos SLO) + kurva latency per step,
+//   dijalankan terpisah untuk H2 dan H3
 // =====================================
 
 const (
@@ -41,8 +50,11 @@ const (
 	fixedPayload       = 512
 )
 
-
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -50,16 +62,41 @@ func main() {
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "High Traffic Stress Test", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "High Traffic Stress Test", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedWorkers, Payload: fixedPayload, RPS: fixedPeakRPS})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	capacity := core.RegisterCapacityFlags(flag.CommandLine, fixedPeakRPS)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
-
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
+	if capacity.Enabled {
+		if err := capacity.Validate(); err != nil {
+			log.Print(err)
+			return 1
+		}
+	}
 
 	totalDuration := fixedRampUpTime + fixedSustainedTime + fixedRampDownTime
+	if capacity.Enabled {
+		totalDuration = capacity.MaxDuration()
+	}
 
 	// ---- Setup Logger ----
 	logLevel := core.LogLevelNormal
@@ -73,34 +110,56 @@ func main() {
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("high-traffic", map[string]interface{}{
+	config := map[string]interface{}{
+		"scenario":       "high-traffic",
 		"pid":            os.Getpid(),
 		"cwd":            cwd,
 		"addr":           *addr,
 		"protocol":       core.ProtocolName(*useH3),
-		
 		"insecure":       *insecure,
-		"workers":        fixedWorkers,
-		"peak_rps":       fixedPeakRPS,
+		"workers":        load.Workers,
+		"peak_rps":       load.RPS,
 		"ramp_up_time":   fixedRampUpTime,
 		"sustained_time": fixedSustainedTime,
 		"ramp_down_time": fixedRampDownTime,
 		"total_duration": totalDuration,
-		"payload":        fixedPayload,
-	})
+		"payload":        load.Payload,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, capacity.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("high-traffic", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-high-traffic")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
 	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Build HTTP client
-	httpClient, closer := core.NewHTTPClient(*useH3, *insecure, logger)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
 	defer closer()
-	client := echov1connect.NewEchoServiceClient(httpClient, *addr)
+	client := core.NewEchoClient(httpClient, *addr)
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Safety timeout (total duration + buffer)
 	go func() {
@@ -116,14 +175,23 @@ func main() {
 	latCh := make(chan core.Record, 1<<20)
 	jobs := make(chan struct{}, 1<<16)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "high-traffic", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
 	var reqCounter atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
 	// Create simple request function
-	requestFn := core.SimpleRequest(fixedPayload)
+	requestFn := policy.Wrap(core.SimpleRequest(load.Payload))
 
 	// Collector goroutine
 	var all []core.Record
@@ -140,8 +208,8 @@ func main() {
 
 	// Start workers
 	var wg sync.WaitGroup
-	wg.Add(fixedWorkers)
-	for i := 0; i < fixedWorkers; i++ {
+	wg.Add(load.Workers)
+	for i := 0; i < load.Workers; i++ {
 		go func(workerID int) {
 			defer wg.Done()
 			logger.Debug("Worker %d started", workerID)
@@ -150,12 +218,33 @@ func main() {
 		}(i)
 	}
 
-	// Start stress test dispatcher
+	// Start stress test dispatcher, or the capacity search in its place
+	var capResult core.CapacityResult
 	var dispWg sync.WaitGroup
 	dispWg.Add(1)
 	go func() {
 		defer dispWg.Done()
-		stressTestDispatcher(ctx, jobs, logger)
+		if !capacity.Enabled {
+			stressTestDispatcher(ctx, load, jobs, counters, logger)
+			return
+		}
+		capResult = capacity.Search(ctx, func(rps int) core.CapacityProbe {
+			mu.Lock()
+			from := len(all)
+			mu.Unlock()
+			stepStart := capacityStep(ctx, capacity, rps, jobs, latCh, counters, logger)
+
+			phase := core.CapacityPhase(rps)
+			var recs []core.Record
+			mu.Lock()
+			for _, r := range all[from:] {
+				if r.Phase == phase {
+					recs = append(recs, r)
+				}
+			}
+			mu.Unlock()
+			return capacity.Evaluate(rps, stepStart, recs)
+		}, logger)
 	}()
 
 	// Start benchmark
@@ -165,63 +254,72 @@ func main() {
 	// Wait for completion
 	dispWg.Wait()
 	cancel()
+	progress.Wait()
 	wg.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
+	sum.Capacity = capResult
 	mu.Unlock()
 
 	// Print results
 	fmt.Printf("\n")
 	logger.Summary(map[string]interface{}{
-		"scenario":       "high_traffic_stress",
-		
-		"protocol":       core.ProtocolName(*useH3),
-		"workers":        fixedWorkers,
-		"peak_rps":       fixedPeakRPS,
-		"total_duration": totalDuration,
-		"samples":        sum.Samples,
-		"ok_rate_%":      fmt.Sprintf("%.2f", sum.OKRatePct),
-		"rps":            fmt.Sprintf("%.2f", sum.RPS),
-		"p50_ms":         fmt.Sprintf("%.6f", sum.P50ms),
-		"p90_ms":         fmt.Sprintf("%.6f", sum.P90ms),
-		"p95_ms":         fmt.Sprintf("%.6f", sum.P95ms),
-		"p99_ms":         fmt.Sprintf("%.6f", sum.P99ms),
-		"mean_ms":        fmt.Sprintf("%.6f", sum.Meanms),
-		"min_ms":         fmt.Sprintf("%.6f", sum.Minms),
-		"max_ms":         fmt.Sprintf("%.6f", sum.Maxms),
+		"scenario": "high_traffic_stress",
+
+		"protocol":         core.ProtocolName(*useH3),
+		"workers":          load.Workers,
+		"peak_rps":         load.RPS,
+		"total_duration":   totalDuration,
+		"samples":          sum.Samples,
+		"ok_rate_%":        fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":         sum.Timeouts,
+		"rps":              fmt.Sprintf("%.2f", sum.RPS),
+		"p50_ms":           fmt.Sprintf("%.6f", sum.P50ms),
+		"p90_ms":           fmt.Sprintf("%.6f", sum.P90ms),
+		"p95_ms":           fmt.Sprintf("%.6f", sum.P95ms),
+		"p99_ms":           fmt.Sprintf("%.6f", sum.P99ms),
+		"sustained_p99_ms": fmt.Sprintf("%.6f", sum.Phase("sustained").P99ms),
+		"mean_ms":          fmt.Sprintf("%.6f", sum.Meanms),
+		"min_ms":           fmt.Sprintf("%.6f", sum.Minms),
+		"max_ms":           fmt.Sprintf("%.6f", sum.Maxms),
 	})
 
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.Capacity(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
 
 	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
 
 // stressTestDispatcher implements ramp-up -> sustained -> ramp-down pattern
-func stressTestDispatcher(ctx context.Context, jobs chan<- struct{}, logger *core.Logger) {
+func stressTestDispatcher(ctx context.Context, load *core.Load, jobs chan<- struct{}, counters *core.Counters, logger *core.Logger) {
 	logger.Info("Stress test dispatcher started")
 
 	start := time.Now()
 
 	// PHASE 1: RAMP-UP
-	logger.Info("PHASE 1: RAMP-UP (0 -> %d RPS over %v)", fixedPeakRPS, fixedRampUpTime)
+	logger.Info("PHASE 1: RAMP-UP (0 -> %d RPS over %v)", load.RPS, fixedRampUpTime)
+	counters.SetPhase("ramp-up", 0)
 	rampUpEnd := start.Add(fixedRampUpTime)
 
 	for time.Now().Before(rampUpEnd) {
@@ -237,11 +335,12 @@ func stressTestDispatcher(ctx context.Context, jobs chan<- struct{}, logger *cor
 		if progress > 1.0 {
 			progress = 1.0
 		}
-		currentRPS := int(float64(fixedPeakRPS) * progress)
+		currentRPS := int(float64(load.RPS) * progress)
 		if currentRPS < 100 {
 			currentRPS = 100
 		}
 
+		counters.SetTargetRPS(currentRPS)
 		interval := time.Second / time.Duration(currentRPS)
 		select {
 		case jobs <- struct{}{}:
@@ -251,9 +350,11 @@ func stressTestDispatcher(ctx context.Context, jobs chan<- struct{}, logger *cor
 	}
 
 	// PHASE 2: SUSTAINED HIGH LOAD
-	logger.Info("PHASE 2: SUSTAINED (maintain %d RPS for %v)", fixedPeakRPS, fixedSustainedTime)
+	logger.Info("PHASE 2: SUSTAINED (maintain %d RPS for %v)", load.RPS, fixedSustainedTime)
+	counters.SetPhase("sustained", 0)
+	counters.SetTargetRPS(load.RPS)
 	sustainedEnd := time.Now().Add(fixedSustainedTime)
-	interval := time.Second / time.Duration(fixedPeakRPS)
+	interval := time.Second / time.Duration(load.RPS)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -271,7 +372,8 @@ func stressTestDispatcher(ctx context.Context, jobs chan<- struct{}, logger *cor
 	}
 
 	// PHASE 3: RAMP-DOWN
-	logger.Info("PHASE 3: RAMP-DOWN (%d RPS -> 0 over %v)", fixedPeakRPS, fixedRampDownTime)
+	logger.Info("PHASE 3: RAMP-DOWN (%d RPS -> 0 over %v)", load.RPS, fixedRampDownTime)
+	counters.SetPhase("ramp-down", 0)
 	rampDownStart := time.Now()
 	rampDownEnd := rampDownStart.Add(fixedRampDownTime)
 
@@ -288,11 +390,12 @@ func stressTestDispatcher(ctx context.Context, jobs chan<- struct{}, logger *cor
 		if progress > 1.0 {
 			progress = 1.0
 		}
-		currentRPS := int(float64(fixedPeakRPS) * (1.0 - progress))
+		currentRPS := int(float64(load.RPS) * (1.0 - progress))
 		if currentRPS < 100 {
 			currentRPS = 100
 		}
 
+		counters.SetTargetRPS(currentRPS)
 		interval := time.Second / time.Duration(currentRPS)
 		select {
 		case jobs <- struct{}{}:
@@ -303,3 +406,56 @@ func stressTestDispatcher(ctx context.Context, jobs chan<- struct{}, logger *cor
 
 	logger.Info("Stress test dispatcher completed all phases")
 }
+
+// capacityStep offers rps for the hold period under the step's phase, then
+// drops queued jobs and waits for in-flight requests so every record of the
+// step reaches the collector before it is evaluated. Returns the step start
+func capacityStep(ctx context.Context, c *core.Capacity, rps int, jobs chan struct{}, latCh chan core.Record, counters *core.Counters, logger *core.Logger) time.Time {
+	logger.Info("CAPACITY STEP: %d RPS for %v", rps, c.Hold)
+	counters.SetPhase(core.CapacityPhase(rps), 0)
+	counters.SetTargetRPS(rps)
+	start := time.Now()
+
+	// Tickers can't fire every few microseconds, so each tick offers the
+	// jobs due since the step start
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	end := time.NewTimer(c.Hold)
+	defer end.Stop()
+	offered := 0
+offer:
+	for {
+		select {
+		case <-ctx.Done():
+			return start
+		case <-end.C:
+			break offer
+		case now := <-ticker.C:
+			due := int(now.Sub(start).Seconds() * float64(rps))
+			for ; offered < due; offered++ {
+				select {
+				case jobs <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+	counters.SetTargetRPS(0)
+
+	// Drain: queued jobs would start under this step's phase after its end
+	for {
+		select {
+		case <-jobs:
+			continue
+		default:
+		}
+		break
+	}
+	deadline := time.Now().Add(c.DrainTimeout)
+	for (counters.InFlight.Load() > 0 || len(latCh) > 0) && time.Now().Before(deadline) && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Records are sent right after InFlight drops, give the collector a beat
+	time.Sleep(50 * time.Millisecond)
+	return start
+}
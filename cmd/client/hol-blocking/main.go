@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"h3-vs-h2-k6/cmd/client/core"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
+)
+
+// =====================================
+// HEAD-OF-LINE BLOCKING UNDER PACKET LOSS
+// =====================================
+// Skenario untuk mengisolasi head-of-line blocking di level transport:
+// banyak stream dengan ukuran campuran di-multiplex di atas SATU koneksi
+// melewati jalur yang lossy.
+//
+// Pattern (rounds bergantian):
+// - baseline round: hanya small streams secara paralel
+// - mixed round: large streams dimulai dulu, lalu small streams menyusul
+//
+// Di HTTP/2 (TCP), satu paket yang hilang milik large stream menahan
+// semua stream lain di koneksi yang sama sampai retransmisi selesai.
+// Di HTTP/3 (QUIC), loss hanya menahan stream pemilik paket tersebut.
+// Latency inflation = latency small stream di mixed round dibanding
+// latency small stream di baseline round (loss rate yang sama).
+//
+// Catatan: Skenario ini memerlukan network impairment tools untuk
+// mensimulasikan packet loss (lihat uplink-loss), misalnya:
+// - Linux: sudo tc qdisc add dev eth0 root netem loss 1%
+//
+// FIXED CONFIGURATION:
+// Config: 1 connection, 200 rounds @ 100ms, 32 small streams (512B)
+// + 2 large streams (256KB) per mixed round
+// Total: 100 baseline rounds * 32 + 100 mixed rounds * 34 = 6,600 requests
+// =====================================
+
+const (
+	fixedRounds         = 200
+	fixedRoundInterval  = 100 * time.Millisecond
+	fixedSmallStreams   = 32
+	fixedLargeStreams   = 2
+	fixedSmallPayload   = 512
+	fixedLargePayload   = 256 * 1024 // 256KB
+	fixedLargeHeadStart = 5 * time.Millisecond
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	// -------- Flags --------
+	var (
+		addr     = flag.String("addr", "https://localhost:8443", "server URL")
+		useH3    = flag.Bool("h3", true, "use HTTP/3 (true) or HTTP/2 (false)")
+		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
+
+		// Output only
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Head-of-Line Blocking Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
+	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
+	flag.Parse()
+
+	// ---- Setup Logger ----
+	logLevel := core.LogLevelNormal
+	if *quiet {
+		logLevel = core.LogLevelMinimal
+	}
+	if *verbose {
+		logLevel = core.LogLevelVerbose
+	}
+	logger := core.NewLogger(logLevel)
+
+	// ---- Startup logging ----
+	cwd, _ := os.Getwd()
+	config := map[string]interface{}{
+		"scenario":         "hol-blocking",
+		"pid":              os.Getpid(),
+		"cwd":              cwd,
+		"addr":             *addr,
+		"protocol":         core.ProtocolName(*useH3),
+		"insecure":         *insecure,
+		"connections":      1,
+		"rounds":           fixedRounds,
+		"round_interval":   fixedRoundInterval,
+		"small_streams":    fixedSmallStreams,
+		"large_streams":    fixedLargeStreams,
+		"small_payload":    fixedSmallPayload,
+		"large_payload":    fixedLargePayload,
+		"large_head_start": fixedLargeHeadStart,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("hol-blocking", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-hol-blocking")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
+
+	// Network impairment reminder
+	logger.Info("NOTE: For head-of-line blocking testing, configure packet loss on the path:")
+	logger.Info("  - macOS: Network Link Conditioner (1-5%% packet loss)")
+	logger.Info("  - Linux: tc qdisc add dev <iface> root netem loss 1%%")
+	logger.Info("  - Windows: clumsy (packet drop 1-5%%)")
+	logger.Info("Without impairment, inflation only reflects bandwidth sharing between streams")
+
+	// Absolutkan output path
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
+
+	// Build HTTP client (single shared connection)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
+	defer closer()
+	client := core.NewEchoClient(httpClient, *addr)
+
+	// Context & cancel
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
+
+	// Channels & counters (one channel per stream class)
+	baseSmallCh := make(chan core.Record, 1<<16)
+	mixedSmallCh := make(chan core.Record, 1<<16)
+	mixedLargeCh := make(chan core.Record, 1<<16)
+	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "hol-blocking", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
+	var reqCounter atomic.Int64
+
+	smallFn := policy.Wrap(core.SimpleRequest(fixedSmallPayload))
+	largeFn := policy.Wrap(core.SimpleRequest(fixedLargePayload))
+
+	// Collector goroutines
+	var colWg sync.WaitGroup
+	baseSmall := collect(&colWg, baseSmallCh)
+	mixedSmall := collect(&colWg, mixedSmallCh)
+	mixedLarge := collect(&colWg, mixedLargeCh)
+
+	// Prime the connection so every round shares the same, already established connection
+	if _, err := smallFn(ctx, client, 0); err != nil {
+		log.Printf("priming request failed: %v", err)
+		return 1
+	}
+
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
+	if !*quiet {
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
+	}
+
+	// Start benchmark
+	start := time.Now()
+	logger.Info("Starting head-of-line blocking benchmark...")
+
+rounds:
+	for round := 0; round < fixedRounds; round++ {
+		select {
+		case <-ctx.Done():
+			logger.Info("Benchmark cancelled at round %d", round)
+			break rounds
+		default:
+		}
+
+		mixed := round%2 == 1
+		var roundWg sync.WaitGroup
+
+		if mixed {
+			// Large streams first so their packets are in flight when small streams start
+			roundWg.Add(fixedLargeStreams)
+			for i := 0; i < fixedLargeStreams; i++ {
+				go func() {
+					defer roundWg.Done()
+					reqID := reqCounter.Add(1)
+					core.DoRequest(ctx, client, mixedLargeCh, counters, logger, reqID, largeFn)
+				}()
+			}
+			time.Sleep(fixedLargeHeadStart)
+		}
+
+		smallCh := baseSmallCh
+		if mixed {
+			smallCh = mixedSmallCh
+		}
+		roundWg.Add(fixedSmallStreams)
+		for i := 0; i < fixedSmallStreams; i++ {
+			go func() {
+				defer roundWg.Done()
+				reqID := reqCounter.Add(1)
+				core.DoRequest(ctx, client, smallCh, counters, logger, reqID, smallFn)
+			}()
+		}
+
+		roundWg.Wait()
+		if round < fixedRounds-1 {
+			time.Sleep(fixedRoundInterval)
+		}
+	}
+
+	cancel()
+	progress.Wait()
+	close(baseSmallCh)
+	close(mixedSmallCh)
+	close(mixedLargeCh)
+	colWg.Wait()
+
+	// Calculate summaries per stream class
+	all := make([]core.Record, 0, len(*baseSmall)+len(*mixedSmall)+len(*mixedLarge))
+	all = append(all, *baseSmall...)
+	all = append(all, *mixedSmall...)
+	all = append(all, *mixedLarge...)
+	cut := window.Resolve(all)
+	sum := cut.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
+	baseSum := cut.Summarize(*baseSmall)
+	smallSum := cut.Summarize(*mixedSmall)
+	largeSum := cut.Summarize(*mixedLarge)
+
+	// Print results
+	fmt.Printf("\n")
+	logger.Summary(map[string]interface{}{
+		"scenario":              "hol_blocking",
+		"protocol":              core.ProtocolName(*useH3),
+		"rounds":                fixedRounds,
+		"small_streams":         fixedSmallStreams,
+		"large_streams":         fixedLargeStreams,
+		"samples":               sum.Samples,
+		"ok_rate_%":             fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":              sum.Timeouts,
+		"baseline_small_p50_ms": fmt.Sprintf("%.6f", baseSum.P50ms),
+		"baseline_small_p99_ms": fmt.Sprintf("%.6f", baseSum.P99ms),
+		"mixed_small_p50_ms":    fmt.Sprintf("%.6f", smallSum.P50ms),
+		"mixed_small_p99_ms":    fmt.Sprintf("%.6f", smallSum.P99ms),
+		"mixed_large_p50_ms":    fmt.Sprintf("%.6f", largeSum.P50ms),
+		"mixed_large_p99_ms":    fmt.Sprintf("%.6f", largeSum.P99ms),
+		"small_inflation_p50":   inflation(smallSum.P50ms, baseSum.P50ms),
+		"small_inflation_p90":   inflation(smallSum.P90ms, baseSum.P90ms),
+		"small_inflation_p99":   inflation(smallSum.P99ms, baseSum.P99ms),
+		"small_inflation_mean":  inflation(smallSum.Meanms, baseSum.Meanms),
+	})
+
+	// Also log in standard format for backward compatibility
+	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
+		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
+
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+	log.Printf("hol | small p50 %.3fms -> %.3fms (%s) p99 %.3fms -> %.3fms (%s)",
+		baseSum.P50ms, smallSum.P50ms, inflation(smallSum.P50ms, baseSum.P50ms),
+		baseSum.P99ms, smallSum.P99ms, inflation(smallSum.P99ms, baseSum.P99ms))
+
+	out.Write(*label, all, sum, logger)
+
+	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
+}
+
+// collect drains a record channel into a slice until the channel is closed
+func collect(wg *sync.WaitGroup, ch <-chan core.Record) *[]core.Record {
+	var out []core.Record
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for r := range ch {
+			out = append(out, r)
+		}
+	}()
+	return &out
+}
+
+// inflation formats the latency increase of mixed vs baseline as "+X.XXms (Y.YYx)"
+func inflation(mixed, baseline float64) string {
+	if baseline <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.3fms (%.2fx)", mixed-baseline, mixed/baseline)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -11,7 +12,9 @@ import (
 	"syscall"
 	"time"
 
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/cmd/client/core"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -31,75 +34,136 @@ const (
 	fixedJitter  = 100 * time.Millisecond
 )
 
-// ---- main ----
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
 		useH3    = flag.Bool("h3", true, "use HTTP/3 (true) or HTTP/2 (false)")
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
-		// output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "Low Traffic Baseline", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
+		// Output only
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Low Traffic Baseline", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedClients, Payload: fixedPayload, Duration: fixedDur})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	// ---- Setup Logger ----
+	logLevel := core.LogLevelNormal
+	if *quiet {
+		logLevel = core.LogLevelMinimal
+	}
+	if *verbose {
+		logLevel = core.LogLevelVerbose
+	}
+	logger := core.NewLogger(logLevel)
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	log.Printf("[startup] pid=%d cwd=%s", os.Getpid(), cwd)
-	log.Printf("[startup] argv=%q", os.Args)
-	log.Printf("[config] clients=%d payload=%d duration=%v", fixedClients, fixedPayload, fixedDur)
-	log.Printf("[config] mode=periodic period=%v jitter=%v", fixedPeriod, fixedJitter)
-
-	// Absolutkan output path (kalau diisi user)
-	csvAbs := absOrEmpty(*csvPath, cwd)
-	htmlAbs := absOrEmpty(*htmlPath, cwd)
+	config := map[string]interface{}{
+		"scenario": "low-traffic",
+		"pid":      os.Getpid(),
+		"cwd":      cwd,
+		"addr":     *addr,
+		"protocol": core.ProtocolName(*useH3),
+		"insecure": *insecure,
+		"clients":  load.Workers,
+		"payload":  load.Payload,
+		"duration": load.Duration,
+		"mode":     "periodic",
+		"period":   fixedPeriod,
+		"jitter":   fixedJitter,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("low-traffic", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-low-traffic")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
+
+	// Absolutkan output path
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Build HTTP client
-	httpClient, closer := newHTTPClient(*useH3, *insecure)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
 	defer closer()
-	client := echov1connect.NewEchoServiceClient(httpClient, *addr)
+	client := core.NewEchoClient(httpClient, *addr)
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Timer durasi
 	go func() {
 		select {
-		case <-time.After(fixedDur):
-			log.Printf("[timer] duration elapsed: %v → cancel()", fixedDur)
+		case <-time.After(load.Duration):
+			logger.Info("Duration elapsed: %v -> stopping", load.Duration)
 			cancel()
 		case <-ctx.Done():
 		}
 	}()
 
 	// Channels & counters
-	latCh := make(chan rec, 1<<20)
-	var totalOK, totalErr atomic.Uint64
-	var errLogCount atomic.Int64
+	latCh := make(chan core.Record, 1<<20)
+	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "low-traffic", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
+	var reqCounter atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go progressPrinter(ctx, &totalOK, &totalErr)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
-	// Worker pool
-	var wg sync.WaitGroup
-	wg.Add(fixedClients)
+	// Create simple request function
+	requestFn := policy.Wrap(core.SimpleRequest(load.Payload))
 
-	// Worker launcher - periodic mode only
-	for i := 0; i < fixedClients; i++ {
-		go func(id int) {
-			defer wg.Done()
-			runPeriodicWorker(ctx, client, latCh, &totalOK, &totalErr, &errLogCount, fixedPeriod, fixedJitter, fixedPayload, 0)
-		}(i)
-	}
-
-	// Collector
-	var all []rec
+	// Collector goroutine
+	var all []core.Record
 	var mu sync.Mutex
 	doneCol := make(chan struct{})
 	go func() {
@@ -111,36 +175,73 @@ func main() {
 		}
 	}()
 
-	// Tunggu selesai
+	// Start benchmark
 	start := time.Now()
-	<-ctx.Done() // tunggu timer durasi atau signal
+	logger.Info("Starting low traffic baseline benchmark...")
+
+	// Start workers - periodic mode only
+	var wg sync.WaitGroup
+	wg.Add(load.Workers)
+	for i := 0; i < load.Workers; i++ {
+		go func(workerID int) {
+			defer wg.Done()
+			logger.Debug("Worker %d started", workerID)
+			core.PeriodicWorker(ctx, client, latCh, counters, logger, fixedPeriod, fixedJitter, requestFn, &reqCounter)
+			logger.Debug("Worker %d stopped", workerID)
+		}(i)
+	}
+
+	// Wait for completion (duration timer or signal)
+	<-ctx.Done()
+	progress.Wait()
 	wg.Wait()
 	close(latCh)
 	<-doneCol
 
-	// Ringkas & tulis output
+	// Calculate summary
 	mu.Lock()
-	sum := summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
 	mu.Unlock()
 
+	// Print results
+	fmt.Printf("\n")
+	logger.Summary(map[string]interface{}{
+		"scenario":  "low_traffic",
+		"protocol":  core.ProtocolName(*useH3),
+		"clients":   load.Workers,
+		"period":    fixedPeriod,
+		"jitter":    fixedJitter,
+		"samples":   sum.Samples,
+		"ok_rate_%": fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":  sum.Timeouts,
+		"rps":       fmt.Sprintf("%.2f", sum.RPS),
+		"p50_ms":    fmt.Sprintf("%.6f", sum.P50ms),
+		"p90_ms":    fmt.Sprintf("%.6f", sum.P90ms),
+		"p95_ms":    fmt.Sprintf("%.6f", sum.P95ms),
+		"p99_ms":    fmt.Sprintf("%.6f", sum.P99ms),
+		"mean_ms":   fmt.Sprintf("%.6f", sum.Meanms),
+		"min_ms":    fmt.Sprintf("%.6f", sum.Minms),
+		"max_ms":    fmt.Sprintf("%.6f", sum.Maxms),
+	})
+
+	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Tulis CSV/HTML kalau diminta
-	if csvAbs != "" {
-		if err := writeCSV(csvAbs, all); err != nil {
-			log.Printf("ERROR write csv to %s: %v", csvAbs, err)
-		} else {
-			log.Printf("csv written: %s", csvAbs)
-		}
-	}
-	if htmlAbs != "" {
-		if err := writeHTML(htmlAbs, *label, sum); err != nil {
-			log.Printf("ERROR write html to %s: %v", htmlAbs, err)
-		} else {
-			log.Printf("html written: %s", htmlAbs)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
 
-	log.Printf("[runtime] %v", time.Since(start))
+	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
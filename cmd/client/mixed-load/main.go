@@ -16,6 +16,8 @@ import (
 	"h3-vs-h2-k6/cmd/client/core"
 	echov1 "h3-vs-h2-k6/echo/v1"
 	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -45,6 +47,10 @@ const (
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -52,13 +58,29 @@ func main() {
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "Mixed Load Benchmark", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Mixed Load Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedWorkers, RPS: fixedTargetRPS, Duration: fixedDuration})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
 
 	// Get fixed config for level
 
@@ -74,42 +96,63 @@ func main() {
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("mixed-load", map[string]interface{}{
+	config := map[string]interface{}{
+		"scenario":       "mixed-load",
 		"pid":            os.Getpid(),
 		"cwd":            cwd,
 		"addr":           *addr,
 		"protocol":       core.ProtocolName(*useH3),
-		
 		"insecure":       *insecure,
-		"workers":        fixedWorkers,
-		"duration":       fixedDuration,
-		"target_rps":     fixedTargetRPS,
+		"workers":        load.Workers,
+		"duration":       load.Duration,
+		"target_rps":     load.RPS,
 		"small_pct":      fixedSmallPct,
 		"medium_pct":     fixedMediumPct,
 		"large_pct":      fixedLargePct,
 		"small_payload":  fixedSmallPayload,
 		"medium_payload": fixedMediumPayload,
 		"large_payload":  fixedLargePayload,
-	})
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("mixed-load", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-mixed-load")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
 	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Build HTTP client
-	httpClient, closer := core.NewHTTPClient(*useH3, *insecure, logger)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
 	defer closer()
-	client := echov1connect.NewEchoServiceClient(httpClient, *addr)
+	client := core.NewEchoClient(httpClient, *addr)
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Timer untuk durasi test
 	go func() {
 		select {
-		case <-time.After(fixedDuration):
-			logger.Info("Duration elapsed: %v -> stopping", fixedDuration)
+		case <-time.After(load.Duration):
+			logger.Info("Duration elapsed: %v -> stopping", load.Duration)
 			cancel()
 		case <-ctx.Done():
 		}
@@ -119,13 +162,22 @@ func main() {
 	latCh := make(chan core.Record, 1<<20)
 	jobs := make(chan requestJob, 1<<16)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "mixed-load", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
 	var reqCounter atomic.Int64
 
 	// Track request type distribution
 	var smallCount, mediumCount, largeCount atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
 	// Collector goroutine
@@ -141,15 +193,22 @@ func main() {
 		}
 	}()
 
+	// Request function per class, wrapped in the retry/hedge policy once
+	requestFns := map[string]core.RequestFunc{
+		"small":  policy.Wrap(classRequest("small", fixedSmallPayload)),
+		"medium": policy.Wrap(classRequest("medium", fixedMediumPayload)),
+		"large":  policy.Wrap(classRequest("large", fixedLargePayload)),
+	}
+
 	// Start workers
 	var wg sync.WaitGroup
-	wg.Add(fixedWorkers)
-	for i := 0; i < fixedWorkers; i++ {
+	wg.Add(load.Workers)
+	for i := 0; i < load.Workers; i++ {
 		go func(workerID int) {
 			defer wg.Done()
 			logger.Debug("Worker %d started", workerID)
 			mixedLoadWorker(ctx, client, latCh, counters, logger, jobs, &reqCounter,
-				&smallCount, &mediumCount, &largeCount)
+				&smallCount, &mediumCount, &largeCount, requestFns)
 			logger.Debug("Worker %d stopped", workerID)
 		}(i)
 	}
@@ -159,7 +218,8 @@ func main() {
 	dispWg.Add(1)
 	go func() {
 		defer dispWg.Done()
-		mixedLoadDispatcher(ctx, jobs, logger)
+		counters.SetTargetRPS(load.RPS)
+		mixedLoadDispatcher(ctx, load, jobs, logger)
 	}()
 
 	// Start benchmark
@@ -169,13 +229,17 @@ func main() {
 	// Wait for completion
 	dispWg.Wait()
 	cancel()
+	progress.Wait()
 	wg.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
 	mu.Unlock()
 
 	// Get request type counts
@@ -187,47 +251,52 @@ func main() {
 	// Print results
 	fmt.Printf("\n")
 	logger.Summary(map[string]interface{}{
-		"scenario":        "mixed_load",
-		
-		"protocol":        core.ProtocolName(*useH3),
-		"workers":         fixedWorkers,
-		"target_rps":      fixedTargetRPS,
-		"small_requests":  small,
-		"medium_requests": medium,
-		"large_requests":  large,
-		"total_requests":  total,
+		"scenario": "mixed_load",
+
+		"protocol":          core.ProtocolName(*useH3),
+		"workers":           load.Workers,
+		"target_rps":        load.RPS,
+		"small_requests":    small,
+		"medium_requests":   medium,
+		"large_requests":    large,
+		"total_requests":    total,
 		"small_pct_actual":  fmt.Sprintf("%.1f", float64(small)/float64(total)*100),
 		"medium_pct_actual": fmt.Sprintf("%.1f", float64(medium)/float64(total)*100),
 		"large_pct_actual":  fmt.Sprintf("%.1f", float64(large)/float64(total)*100),
-		"samples":         sum.Samples,
-		"ok_rate_%":       fmt.Sprintf("%.2f", sum.OKRatePct),
-		"rps":             fmt.Sprintf("%.2f", sum.RPS),
-		"p50_ms":          fmt.Sprintf("%.6f", sum.P50ms),
-		"p90_ms":          fmt.Sprintf("%.6f", sum.P90ms),
-		"p95_ms":          fmt.Sprintf("%.6f", sum.P95ms),
-		"p99_ms":          fmt.Sprintf("%.6f", sum.P99ms),
-		"mean_ms":         fmt.Sprintf("%.6f", sum.Meanms),
-		"min_ms":          fmt.Sprintf("%.6f", sum.Minms),
-		"max_ms":          fmt.Sprintf("%.6f", sum.Maxms),
+		"samples":           sum.Samples,
+		"ok_rate_%":         fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":          sum.Timeouts,
+		"rps":               fmt.Sprintf("%.2f", sum.RPS),
+		"p50_ms":            fmt.Sprintf("%.6f", sum.P50ms),
+		"p90_ms":            fmt.Sprintf("%.6f", sum.P90ms),
+		"p95_ms":            fmt.Sprintf("%.6f", sum.P95ms),
+		"p99_ms":            fmt.Sprintf("%.6f", sum.P99ms),
+		"mean_ms":           fmt.Sprintf("%.6f", sum.Meanms),
+		"min_ms":            fmt.Sprintf("%.6f", sum.Minms),
+		"max_ms":            fmt.Sprintf("%.6f", sum.Maxms),
+		"small_p99_ms":      fmt.Sprintf("%.6f", sum.Class("small").P99ms),
+		"medium_p99_ms":     fmt.Sprintf("%.6f", sum.Class("medium").P99ms),
+		"large_p99_ms":      fmt.Sprintf("%.6f", sum.Class("large").P99ms),
 	})
 
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Classes(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
 
 	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
 
 // requestJob represents a request job with specific payload size
@@ -237,10 +306,10 @@ type requestJob struct {
 }
 
 // mixedLoadDispatcher dispatches mixed request types at target RPS
-func mixedLoadDispatcher(ctx context.Context, jobs chan<- requestJob, logger *core.Logger) {
-	logger.Info("Mixed load dispatcher started: target RPS=%d", fixedTargetRPS)
+func mixedLoadDispatcher(ctx context.Context, load *core.Load, jobs chan<- requestJob, logger *core.Logger) {
+	logger.Info("Mixed load dispatcher started: target RPS=%d", load.RPS)
 
-	interval := time.Second / time.Duration(fixedTargetRPS)
+	interval := time.Second / time.Duration(load.RPS)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -276,6 +345,21 @@ func mixedLoadDispatcher(ctx context.Context, jobs chan<- requestJob, logger *co
 	}
 }
 
+// classRequest creates a request of one class with its payload size
+func classRequest(reqType string, payloadSize int) core.RequestFunc {
+	return func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
+		req := connect.NewRequest(&echov1.EchoRequest{
+			Message: reqType,
+			Payload: make([]byte, payloadSize),
+		})
+		resp, err := cl.Unary(ctx, req)
+		if err != nil {
+			return 0, err
+		}
+		return len(resp.Msg.GetPayload()), nil
+	}
+}
+
 // mixedLoadWorker handles mixed request types
 func mixedLoadWorker(
 	ctx context.Context,
@@ -286,6 +370,7 @@ func mixedLoadWorker(
 	jobs <-chan requestJob,
 	reqCounter *atomic.Int64,
 	smallCount, mediumCount, largeCount *atomic.Int64,
+	requestFns map[string]core.RequestFunc,
 ) {
 	for {
 		select {
@@ -305,21 +390,8 @@ func mixedLoadWorker(
 				largeCount.Add(1)
 			}
 
-			// Create request function with specific payload size
-			requestFn := func(ctx context.Context, cl echov1connect.EchoServiceClient, reqID int64) (int, error) {
-				req := connect.NewRequest(&echov1.EchoRequest{
-					Message: job.reqType,
-					Payload: make([]byte, job.payloadSize),
-				})
-				resp, err := cl.Unary(ctx, req)
-				if err != nil {
-					return 0, err
-				}
-				return len(resp.Msg.GetPayload()), nil
-			}
-
 			reqID := reqCounter.Add(1)
-			core.DoRequest(ctx, cl, latCh, counters, logger, reqID, requestFn)
+			core.DoRequest(core.WithClass(ctx, job.reqType), cl, latCh, counters, logger, reqID, requestFns[job.reqType])
 		}
 	}
 }
@@ -2,11 +2,9 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"sync"
@@ -14,10 +12,9 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/quic-go/quic-go/http3"
-	"golang.org/x/net/http2"
 	"h3-vs-h2-k6/cmd/client/core"
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -48,15 +45,18 @@ import (
 // =====================================
 
 const (
-	fixedWorkers          = 1000
-	fixedCycles           = 50
-	fixedRequestsPerPhase = 1
+	fixedWorkers           = 1000
+	fixedCycles            = 50
+	fixedRequestsPerPhase  = 1
 	fixedMigrationInterval = 1 * time.Second
-	fixedPayload          = 512
+	fixedPayload           = 512
 )
 
-
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -64,14 +64,29 @@ func main() {
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "NAT Rebinding Benchmark", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "NAT Rebinding Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedWorkers, Payload: fixedPayload})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
-
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
 
 	// ---- Setup Logger ----
 	logLevel := core.LogLevelNormal
@@ -85,20 +100,35 @@ func main() {
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("nat-rebinding", map[string]interface{}{
+	config := map[string]interface{}{
+		"scenario":           "nat-rebinding",
 		"pid":                os.Getpid(),
 		"cwd":                cwd,
 		"addr":               *addr,
 		"protocol":           core.ProtocolName(*useH3),
-		
 		"insecure":           *insecure,
-		"workers":            fixedWorkers,
+		"workers":            load.Workers,
 		"cycles":             fixedCycles,
 		"requests_per_phase": fixedRequestsPerPhase,
 		"migration_interval": fixedMigrationInterval,
-		"payload":            fixedPayload,
-		"total_requests":     fixedWorkers * fixedCycles * fixedRequestsPerPhase * 2, // 2 phases per cycle
-	})
+		"payload":            load.Payload,
+		"total_requests":     load.Workers * fixedCycles * fixedRequestsPerPhase * 2, // 2 phases per cycle
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("nat-rebinding", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-nat-rebinding")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
 	// Migration simulation note
 	logger.Info("NOTE: This simulates connection migration by forcing reconnection")
@@ -109,21 +139,36 @@ func main() {
 	logger.Info("This benchmark measures reconnection overhead as proxy for migration cost")
 
 	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Channels & counters
 	latCh := make(chan core.Record, 1<<20)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "nat-rebinding", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
 	var reqCounter atomic.Int64
 	var migrationCount atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
 	// Collector goroutine
@@ -143,11 +188,14 @@ func main() {
 	start := time.Now()
 	logger.Info("Starting NAT rebinding/migration benchmark...")
 
+	// Request function shared by every worker, wrapped in the retry/hedge policy once
+	requestFn := policy.Wrap(core.SimpleRequest(load.Payload))
+
 	// Start workers - each simulates migration cycles
 	var wg sync.WaitGroup
-	wg.Add(fixedWorkers)
+	wg.Add(load.Workers)
 
-	for i := 0; i < fixedWorkers; i++ {
+	for i := 0; i < load.Workers; i++ {
 		go func(workerID int) {
 			defer wg.Done()
 			logger.Debug("Worker %d started", workerID)
@@ -161,9 +209,9 @@ func main() {
 				}
 
 				// PHASE 1: Create connection and send requests
-				httpClient1, closer1 := newHTTPClient(*useH3, *insecure)
-				client1 := echov1connect.NewEchoServiceClient(httpClient1, *addr)
-				requestFn := core.SimpleRequest(fixedPayload)
+				httpClient1, closer1 := core.BuildHTTPClient(*useH3, *insecure, tcfg)
+				client1 := core.NewEchoClient(httpClient1, *addr)
+				preCtx := core.WithPhase(ctx, "pre-migration", cycle)
 
 				for req := 0; req < fixedRequestsPerPhase; req++ {
 					select {
@@ -174,7 +222,7 @@ func main() {
 					}
 
 					reqID := reqCounter.Add(1)
-					core.DoRequest(ctx, client1, latCh, counters, logger, reqID, requestFn)
+					core.DoRequest(preCtx, client1, latCh, counters, logger, reqID, requestFn)
 				}
 
 				// Simulate migration interval (network switch delay)
@@ -185,8 +233,9 @@ func main() {
 				migrationCount.Add(1)
 
 				// PHASE 2: Create NEW connection (simulate post-migration)
-				httpClient2, closer2 := newHTTPClient(*useH3, *insecure)
-				client2 := echov1connect.NewEchoServiceClient(httpClient2, *addr)
+				httpClient2, closer2 := core.BuildHTTPClient(*useH3, *insecure, tcfg)
+				client2 := core.NewEchoClient(httpClient2, *addr)
+				postCtx := core.WithPhase(ctx, "post-migration", cycle)
 
 				for req := 0; req < fixedRequestsPerPhase; req++ {
 					select {
@@ -197,7 +246,7 @@ func main() {
 					}
 
 					reqID := reqCounter.Add(1)
-					core.DoRequest(ctx, client2, latCh, counters, logger, reqID, requestFn)
+					core.DoRequest(postCtx, client2, latCh, counters, logger, reqID, requestFn)
 				}
 
 				// Close connection before next cycle
@@ -211,12 +260,16 @@ func main() {
 	// Wait for all workers to finish
 	wg.Wait()
 	cancel()
+	progress.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
 	mu.Unlock()
 
 	migrations := migrationCount.Load()
@@ -224,61 +277,44 @@ func main() {
 	// Print results
 	fmt.Printf("\n")
 	logger.Summary(map[string]interface{}{
-		"scenario":           "nat_rebinding",
-		
-		"protocol":           core.ProtocolName(*useH3),
-		"workers":            fixedWorkers,
-		"cycles":             fixedCycles,
-		"migrations":         migrations,
-		"requests_per_phase": fixedRequestsPerPhase,
-		"total_requests":     fixedWorkers * fixedCycles * fixedRequestsPerPhase * 2,
-		"samples":            sum.Samples,
-		"ok_rate_%":          fmt.Sprintf("%.2f", sum.OKRatePct),
-		"rps":                fmt.Sprintf("%.2f", sum.RPS),
-		"p50_ms":             fmt.Sprintf("%.6f", sum.P50ms),
-		"p90_ms":             fmt.Sprintf("%.6f", sum.P90ms),
-		"p95_ms":             fmt.Sprintf("%.6f", sum.P95ms),
-		"p99_ms":             fmt.Sprintf("%.6f", sum.P99ms),
-		"mean_ms":            fmt.Sprintf("%.6f", sum.Meanms),
-		"min_ms":             fmt.Sprintf("%.6f", sum.Minms),
-		"max_ms":             fmt.Sprintf("%.6f", sum.Maxms),
+		"scenario": "nat_rebinding",
+
+		"protocol":                    core.ProtocolName(*useH3),
+		"workers":                     load.Workers,
+		"cycles":                      fixedCycles,
+		"migrations":                  migrations,
+		"requests_per_phase":          fixedRequestsPerPhase,
+		"total_requests":              load.Workers * fixedCycles * fixedRequestsPerPhase * 2,
+		"samples":                     sum.Samples,
+		"ok_rate_%":                   fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":                    sum.Timeouts,
+		"rps":                         fmt.Sprintf("%.2f", sum.RPS),
+		"p50_ms":                      fmt.Sprintf("%.6f", sum.P50ms),
+		"p90_ms":                      fmt.Sprintf("%.6f", sum.P90ms),
+		"p95_ms":                      fmt.Sprintf("%.6f", sum.P95ms),
+		"p99_ms":                      fmt.Sprintf("%.6f", sum.P99ms),
+		"post_migration_first_p50_ms": fmt.Sprintf("%.6f", sum.Phase("post-migration").FirstP50ms),
+		"post_migration_first_p99_ms": fmt.Sprintf("%.6f", sum.Phase("post-migration").FirstP99ms),
+		"mean_ms":                     fmt.Sprintf("%.6f", sum.Meanms),
+		"min_ms":                      fmt.Sprintf("%.6f", sum.Minms),
+		"max_ms":                      fmt.Sprintf("%.6f", sum.Maxms),
 	})
 
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
 
-	logger.Info("Total runtime: %v", time.Since(start))
-}
+	out.Write(*label, all, sum, logger)
 
-// newHTTPClient creates an HTTP client (H2 or H3) with TLS config
-func newHTTPClient(useH3 bool, insecure bool) (*http.Client, func()) {
-	tlsCfg := &tls.Config{
-		MinVersion:         tls.VersionTLS13,
-		InsecureSkipVerify: insecure,
-	}
-
-	if useH3 {
-		tr := &http3.Transport{TLSClientConfig: tlsCfg}
-		return &http.Client{Transport: tr, Timeout: 0}, func() { tr.CloseIdleConnections() }
-	}
-
-	h2 := &http.Transport{
-		TLSClientConfig:   tlsCfg,
-		ForceAttemptHTTP2: true,
-	}
-	_ = http2.ConfigureTransport(h2)
-	return &http.Client{Transport: h2, Timeout: 0}, func() {}
+	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
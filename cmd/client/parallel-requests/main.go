@@ -13,7 +13,8 @@ import (
 	"time"
 
 	"h3-vs-h2-k6/cmd/client/core"
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -28,14 +29,18 @@ import (
 // =====================================
 
 const (
-	fixedClients        = 1000
+	fixedClients         = 1000
 	fixedParallelStreams = 20
-	fixedBatches        = 50
-	fixedBatchInterval  = 30 * time.Millisecond
-	fixedPayload        = 512
+	fixedBatches         = 50
+	fixedBatchInterval   = 30 * time.Millisecond
+	fixedPayload         = 512
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -43,13 +48,29 @@ func main() {
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "Parallel Requests Benchmark", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Parallel Requests Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedClients, Payload: fixedPayload})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
 
 	// ---- Setup Logger ----
 	logLevel := core.LogLevelNormal
@@ -63,43 +84,74 @@ func main() {
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("parallel-requests", map[string]interface{}{
+	config := map[string]interface{}{
+		"scenario":         "parallel-requests",
 		"pid":              os.Getpid(),
 		"cwd":              cwd,
 		"addr":             *addr,
 		"protocol":         core.ProtocolName(*useH3),
 		"insecure":         *insecure,
-		"clients":          fixedClients,
+		"clients":          load.Workers,
 		"parallel_streams": fixedParallelStreams,
 		"batches":          fixedBatches,
 		"batch_interval":   fixedBatchInterval,
-		"payload":          fixedPayload,
-	})
+		"payload":          load.Payload,
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("parallel-requests", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-parallel-requests")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
 	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Build HTTP client
-	httpClient, closer := core.NewHTTPClient(*useH3, *insecure, logger)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
 	defer closer()
-	client := echov1connect.NewEchoServiceClient(httpClient, *addr)
+	client := core.NewEchoClient(httpClient, *addr)
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Channels & counters
 	latCh := make(chan core.Record, 1<<20)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "parallel-requests", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
 	var reqCounter atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
 	// Create simple request function
-	requestFn := core.SimpleRequest(fixedPayload)
+	requestFn := policy.Wrap(core.SimpleRequest(load.Payload))
 
 	// Collector goroutine
 	var all []core.Record
@@ -120,9 +172,9 @@ func main() {
 
 	// Start workers
 	var wg sync.WaitGroup
-	wg.Add(fixedClients)
+	wg.Add(load.Workers)
 
-	for i := 0; i < fixedClients; i++ {
+	for i := 0; i < load.Workers; i++ {
 		go func(workerID int) {
 			defer wg.Done()
 			logger.Debug("Worker %d started", workerID)
@@ -164,12 +216,16 @@ func main() {
 	// Wait for all workers to finish
 	wg.Wait()
 	cancel()
+	progress.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
 	mu.Unlock()
 
 	// Print results
@@ -177,12 +233,13 @@ func main() {
 	logger.Summary(map[string]interface{}{
 		"scenario":         "parallel_requests",
 		"protocol":         core.ProtocolName(*useH3),
-		"clients":          fixedClients,
+		"clients":          load.Workers,
 		"parallel_streams": fixedParallelStreams,
 		"batches":          fixedBatches,
-		"total_requests":   fixedClients * fixedBatches * fixedParallelStreams,
+		"total_requests":   load.Workers * fixedBatches * fixedParallelStreams,
 		"samples":          sum.Samples,
 		"ok_rate_%":        fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":         sum.Timeouts,
 		"rps":              fmt.Sprintf("%.2f", sum.RPS),
 		"p50_ms":           fmt.Sprintf("%.6f", sum.P50ms),
 		"p90_ms":           fmt.Sprintf("%.6f", sum.P90ms),
@@ -196,18 +253,18 @@ func main() {
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
 
 	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"h3-vs-h2-k6/cmd/client/core"
+)
+
+// =====================================
+// COMPARISON REPORT
+// =====================================
+// Menggabungkan dua atau lebih hasil JSON (--json dari skenario mana pun)
+// menjadi satu halaman HTML untuk perbandingan H2 vs H3:
+// - Latency CDF di-overlay (sumbu x log scale)
+// - Throughput dan latency over time di-overlay
+// - Tabel delta (persentase terhadap run pertama sebagai baseline)
+// - Konfigurasi run berdampingan
+//
+// Contoh:
+//   bench-report --html results/compare-baseline.html \
+//     results/baseline-h2.json results/baseline-h3.json
+// =====================================
+
+func main() {
+	var (
+		htmlPath = flag.String("html", "results/compare.html", "write comparison HTML")
+		title    = flag.String("title", "", "report title (default: run labels)")
+		labels   = flag.String("labels", "", "comma-separated run labels overriding the labels stored in the results")
+		quiet    = flag.Bool("quiet", false, "suppress progress logs")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] baseline.json other.json [more.json ...]\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	logger := core.NewLoggerFromQuiet(*quiet)
+
+	files := flag.Args()
+	if len(files) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var names []string
+	if *labels != "" {
+		names = strings.Split(*labels, ",")
+		if len(names) != len(files) {
+			log.Fatalf("--labels: %d labels for %d results", len(names), len(files))
+		}
+	}
+
+	runs := make([]core.Result, 0, len(files))
+	for i, f := range files {
+		r, err := core.ReadResult(f)
+		if err != nil {
+			log.Fatalf("read result: %v", err)
+		}
+		switch {
+		case names != nil:
+			r.Label = strings.TrimSpace(names[i])
+		case r.Label == "":
+			r.Label = strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		}
+		runs = append(runs, r)
+	}
+
+	if *title == "" {
+		ls := make([]string, len(runs))
+		for i, r := range runs {
+			ls[i] = r.Label
+		}
+		*title = strings.Join(ls, " vs ")
+	}
+
+	if err := core.WriteCompareHTML(*htmlPath, *title, runs, logger); err != nil {
+		log.Fatalf("write comparison html: %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"h3-vs-h2-k6/cmd/client/core"
+)
+
+// =====================================
+// PARAMETER SWEEP RUNNER
+// =====================================
+// Menjalankan satu skenario berulang kali untuk setiap kombinasi nilai
+// dari satu atau dua parameter (misalnya payload, workers, rps, atau
+// loss rate), untuk setiap protokol, lalu menggabungkan hasilnya menjadi
+// kurva pXX vs parameter.
+//
+// Parameter diteruskan ke skenario sebagai flag (--payload=4096), kecuali
+// ada --param-cmd untuk parameter tersebut: perintah shell dijalankan
+// sebelum setiap run dengan {value} diganti nilai parameter, misalnya
+// untuk mengatur network impairment:
+//
+//   --param loss=0%,1%,3% \
+//   --param-cmd 'loss=sudo tc qdisc replace dev lo root netem loss {value}'
+//
+// Argumen setelah "--" diteruskan apa adanya ke setiap run skenario.
+// =====================================
+
+// scenarioBins maps scenario names to their binaries (see Makefile build-client)
+var scenarioBins = map[string]string{
+	"low-traffic":       "bench-client",
+	"header-bloat":      "bench-header-bloat",
+	"parallel-requests": "bench-parallel",
+	"burst-traffic":     "bench-burst",
+	"cold-start":        "bench-coldstart",
+	"connection-churn":  "bench-churn",
+	"mixed-load":        "bench-mixed",
+	"uplink-loss":       "bench-uplink",
+	"nat-rebinding":     "bench-migration",
+	"high-traffic":      "bench-stress",
+	"hol-blocking":      "bench-hol",
+	"bulk-transfer":     "bench-bulk",
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string     { return strings.Join(*s, " ") }
+func (s *stringList) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	var params, paramCmds stringList
+	var (
+		scenario  = flag.String("scenario", "low-traffic", "scenario to sweep")
+		bin       = flag.String("bin", "", "scenario binary (default: bin/<binary> or PATH)")
+		protocols = flag.String("protocols", "h2,h3", "protocols to run, comma-separated (h2, h3)")
+		h2Addr    = flag.String("h2-addr", "https://localhost:8444", "HTTP/2 server URL")
+		h3Addr    = flag.String("h3-addr", "https://localhost:8443", "HTTP/3 server URL")
+		outDir    = flag.String("out", "results/sweep", "directory for per-run JSON results")
+		jsonPath  = flag.String("json", "", "write combined sweep JSON (default: <out>/sweep.json)")
+		htmlPath  = flag.String("html", "", "write sweep charts HTML (default: <out>/sweep.html)")
+		label     = flag.String("label", "", "report title label (default: scenario name)")
+		quiet     = flag.Bool("quiet", false, "suppress scenario output")
+	)
+	flag.Var(&params, "param", "swept parameter name=v1,v2,... (repeatable, at most 2)")
+	flag.Var(&paramCmds, "param-cmd", "name=shell command run before each run, {value} is replaced (repeatable)")
+	flag.Parse()
+	passthrough := flag.Args()
+
+	logger := core.NewLoggerFromQuiet(false)
+
+	// ---- Parameters ----
+	var sweep []core.SweepParam
+	for _, s := range params {
+		p, err := core.ParseSweepParam(s)
+		if err != nil {
+			log.Fatalf("--param: %v", err)
+		}
+		sweep = append(sweep, p)
+	}
+	if len(sweep) == 0 || len(sweep) > 2 {
+		log.Fatalf("--param: need one or two swept parameters, got %d", len(sweep))
+	}
+	if len(sweep) == 2 && sweep[0].Name == sweep[1].Name {
+		log.Fatalf("--param: %s given twice", sweep[0].Name)
+	}
+	cmds := map[string]string{}
+	for _, s := range paramCmds {
+		name, cmd, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(cmd) == "" {
+			log.Fatalf("--param-cmd: invalid %q, want name=command", s)
+		}
+		cmds[strings.TrimSpace(name)] = cmd
+	}
+
+	var protos []bool
+	for _, p := range strings.Split(*protocols, ",") {
+		switch strings.TrimSpace(p) {
+		case "h2":
+			protos = append(protos, false)
+		case "h3":
+			protos = append(protos, true)
+		default:
+			log.Fatalf("--protocols: unknown protocol %q", p)
+		}
+	}
+
+	binary := *bin
+	if binary == "" {
+		name, ok := scenarioBins[*scenario]
+		if !ok {
+			log.Fatalf("unknown scenario %q", *scenario)
+		}
+		binary = lookupBinary(name)
+	}
+	if *label == "" {
+		*label = *scenario + " sweep"
+	}
+	if *jsonPath == "" {
+		*jsonPath = filepath.Join(*outDir, "sweep.json")
+	}
+	if *htmlPath == "" {
+		*htmlPath = filepath.Join(*outDir, "sweep.html")
+	}
+
+	// ---- Startup logging ----
+	config := map[string]interface{}{
+		"scenario":    *scenario,
+		"binary":      binary,
+		"protocols":   *protocols,
+		"h2_addr":     *h2Addr,
+		"h3_addr":     *h3Addr,
+		"params":      params.String(),
+		"param_cmds":  paramCmds.String(),
+		"passthrough": strings.Join(passthrough, " "),
+		"out":         *outDir,
+	}
+	logger.Startup("sweep", config)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// ---- Combinations: first parameter outermost, protocols innermost so
+	// H2 and H3 runs of one combination are close in time ----
+	combos := []map[string]string{{}}
+	for _, p := range sweep {
+		var next []map[string]string
+		for _, c := range combos {
+			for _, v := range p.Values {
+				m := map[string]string{p.Name: v}
+				for k, cv := range c {
+					m[k] = cv
+				}
+				next = append(next, m)
+			}
+		}
+		combos = next
+	}
+
+	res := core.SweepResult{
+		Label:    *label,
+		Scenario: *scenario,
+		Params:   sweep,
+		Meta:     core.Meta(config),
+	}
+	total := len(combos) * len(protos)
+	run := 0
+	started := time.Now()
+
+sweepLoop:
+	for _, combo := range combos {
+		for _, useH3 := range protos {
+			if ctx.Err() != nil {
+				break sweepLoop
+			}
+			run++
+			proto := core.ProtocolName(useH3)
+			pt := core.SweepPoint{Protocol: proto, Params: combo}
+			logger.Info("Sweep run %d/%d: %s %s", run, total, proto, pt.ParamString(sweep))
+
+			addr := *h2Addr
+			if useH3 {
+				addr = *h3Addr
+			}
+			out := filepath.Join(*outDir, runName(*scenario, useH3, combo, sweep)+".json")
+			args := []string{
+				"--addr", addr,
+				fmt.Sprintf("--h3=%t", useH3),
+				"--json", out,
+				"--quiet",
+				"--label", fmt.Sprintf("%s %s %s", *scenario, proto, pt.ParamString(sweep)),
+			}
+			args = append(args, passthrough...)
+
+			var err error
+			for _, p := range sweep {
+				v := combo[p.Name]
+				if cmd, ok := cmds[p.Name]; ok {
+					if err = runParamCmd(ctx, cmd, v); err != nil {
+						break
+					}
+					continue
+				}
+				args = append(args, fmt.Sprintf("--%s=%s", p.Name, v))
+			}
+			if err == nil {
+				err = runScenario(ctx, binary, args, *quiet)
+			}
+			if err == nil {
+				var r core.Result
+				if r, err = core.ReadResult(out); err == nil {
+					pt = core.NewSweepPoint(proto, combo, r.Summary)
+					pt.File = out
+				}
+			}
+			if err != nil {
+				pt.Error = err.Error()
+			}
+			logger.SweepPoint(pt, sweep)
+			res.Points = append(res.Points, pt)
+		}
+	}
+	if ctx.Err() != nil {
+		logger.Info("Sweep interrupted after %d/%d runs", len(res.Points), total)
+	}
+	logger.Info("Sweep finished: %d runs in %v", len(res.Points), time.Since(started).Round(time.Second))
+
+	if err := core.WriteSweepJSON(*jsonPath, res, logger); err != nil {
+		log.Printf("write sweep json: %v", err)
+	}
+	if err := core.WriteSweepHTML(*htmlPath, res, logger); err != nil {
+		log.Printf("write sweep html: %v", err)
+	}
+}
+
+// lookupBinary prefers the repo's bin/ directory, then PATH
+func lookupBinary(name string) string {
+	if p := filepath.Join("bin", name); fileExists(p) {
+		return p
+	}
+	if p, err := exec.LookPath(name); err == nil {
+		return p
+	}
+	log.Fatalf("scenario binary %s not found in bin/ or PATH (run make build-client or pass --bin)", name)
+	return ""
+}
+
+func fileExists(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && !st.IsDir()
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._=-]+`)
+
+// runName builds a file-safe name for one run, e.g. low-traffic-h3-payload=4096
+func runName(scenario string, useH3 bool, combo map[string]string, sweep []core.SweepParam) string {
+	parts := []string{scenario, "h2"}
+	if useH3 {
+		parts[1] = "h3"
+	}
+	for _, p := range sweep {
+		parts = append(parts, p.Name+"="+combo[p.Name])
+	}
+	return unsafeChars.ReplaceAllString(strings.Join(parts, "-"), "_")
+}
+
+// runParamCmd applies a parameter through a shell command
+func runParamCmd(ctx context.Context, cmd, value string) error {
+	c := exec.CommandContext(ctx, "sh", "-c", strings.ReplaceAll(cmd, "{value}", value))
+	c.Stdout, c.Stderr = os.Stderr, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("param-cmd %q: %w", c.Args[2], err)
+	}
+	return nil
+}
+
+// runScenario runs one scenario; on interrupt the scenario gets SIGINT so it
+// still writes its partial result
+func runScenario(ctx context.Context, binary string, args []string, quiet bool) error {
+	c := exec.CommandContext(ctx, binary, args...)
+	c.Cancel = func() error { return c.Process.Signal(os.Interrupt) }
+	c.WaitDelay = 30 * time.Second
+	if !quiet {
+		c.Stdout, c.Stderr = os.Stderr, os.Stderr
+	}
+	err := c.Run()
+	// Failed --threshold checks still write a complete result
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == core.ThresholdExitCode {
+		return nil
+	}
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%s: %w", filepath.Base(binary), err)
+	}
+	return nil
+}
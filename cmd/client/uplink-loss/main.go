@@ -13,7 +13,8 @@ import (
 	"time"
 
 	"h3-vs-h2-k6/cmd/client/core"
-	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 // =====================================
@@ -45,12 +46,16 @@ import (
 const (
 	fixedWorkers      = 1000
 	fixedTotalUploads = 100
-	fixedUploadSize   = 8 * 1024  // 8KB
+	fixedUploadSize   = 8 * 1024 // 8KB
 	fixedTargetRPS    = 2000
 	fixedDuration     = 120 * time.Second
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	// -------- Flags --------
 	var (
 		addr     = flag.String("addr", "https://localhost:8443", "server URL")
@@ -58,14 +63,29 @@ func main() {
 		insecure = flag.Bool("insecure", true, "skip TLS verify (dev)")
 
 		// Output only
-		csvPath  = flag.String("csv", "", "write CSV after test")
-		htmlPath = flag.String("html", "", "write HTML dashboard after test")
-		label    = flag.String("label", "Uplink Loss Benchmark", "dashboard title label")
-		quiet    = flag.Bool("quiet", false, "suppress progress logs during test")
-		verbose  = flag.Bool("verbose", false, "enable verbose request/response logging")
+		csvPath   = flag.String("csv", "", "write CSV after test")
+		htmlPath  = flag.String("html", "", "write HTML dashboard after test")
+		jsonPath  = flag.String("json", "", "write JSON summary after test")
+		mdPath    = flag.String("md", "", "append Markdown summary after test (e.g. $GITHUB_STEP_SUMMARY)")
+		junitPath = flag.String("junit", "", "write JUnit XML of threshold checks after test")
+		label     = flag.String("label", "Uplink Loss Benchmark", "dashboard title label")
+		quiet     = flag.Bool("quiet", false, "suppress progress logs during test")
+		verbose   = flag.Bool("verbose", false, "enable verbose request/response logging")
 	)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
+	load := core.RegisterLoadFlags(flag.CommandLine, core.Load{Workers: fixedWorkers, Payload: fixedUploadSize, RPS: fixedTargetRPS})
+	window := core.RegisterWindowFlags(flag.CommandLine)
+	live := core.RegisterLiveFlags(flag.CommandLine)
+	metrics := core.RegisterMetricsFlags(flag.CommandLine)
+	srvStats := core.RegisterServerStatsFlags(flag.CommandLine)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	policy := core.RegisterPolicyFlags(flag.CommandLine)
+	thresholds := core.RegisterThresholdFlags(flag.CommandLine)
 	flag.Parse()
-
+	if err := load.Validate(); err != nil {
+		log.Print(err)
+		return 1
+	}
 
 	// ---- Setup Logger ----
 	logLevel := core.LogLevelNormal
@@ -79,19 +99,34 @@ func main() {
 
 	// ---- Startup logging ----
 	cwd, _ := os.Getwd()
-	logger.Startup("uplink-loss", map[string]interface{}{
+	config := map[string]interface{}{
+		"scenario":      "uplink-loss",
 		"pid":           os.Getpid(),
 		"cwd":           cwd,
 		"addr":          *addr,
 		"protocol":      core.ProtocolName(*useH3),
-		
 		"insecure":      *insecure,
-		"workers":       fixedWorkers,
+		"workers":       load.Workers,
 		"total_uploads": fixedTotalUploads,
-		"upload_size":   fixedUploadSize,
-		"target_rps":    fixedTargetRPS,
+		"upload_size":   load.Payload,
+		"target_rps":    load.RPS,
 		"est_duration":  fixedDuration,
-	})
+	}
+	core.MergeFields(config, tcfg.Fields())
+	core.MergeFields(config, window.Fields())
+	core.MergeFields(config, metrics.Fields())
+	core.MergeFields(config, srvStats.Fields())
+	core.MergeFields(config, trc.Fields())
+	core.MergeFields(config, policy.Fields())
+	core.MergeFields(config, thresholds.Fields())
+	logger.Startup("uplink-loss", config)
+
+	shutdownTracing, err := trc.Setup("bench-client-uplink-loss")
+	if err != nil {
+		log.Printf("tracing: %v", err)
+		return 1
+	}
+	defer shutdownTracing(context.Background())
 
 	// Network impairment reminder
 	logger.Info("NOTE: For uplink loss testing, configure network impairment:")
@@ -101,17 +136,23 @@ func main() {
 	logger.Info("Without impairment, this runs as baseline upload benchmark")
 
 	// Absolutkan output path
-	csvAbs := core.AbsOrEmpty(*csvPath, cwd)
-	htmlAbs := core.AbsOrEmpty(*htmlPath, cwd)
+	out := core.Outputs{
+		CSV:      core.AbsOrEmpty(*csvPath, cwd),
+		HTML:     core.AbsOrEmpty(*htmlPath, cwd),
+		JSON:     core.AbsOrEmpty(*jsonPath, cwd),
+		Markdown: core.AbsOrEmpty(*mdPath, cwd),
+		JUnit:    core.AbsOrEmpty(*junitPath, cwd),
+	}
 
 	// Build HTTP client
-	httpClient, closer := core.NewHTTPClient(*useH3, *insecure, logger)
+	httpClient, closer := core.NewHTTPClientWithConfig(*useH3, *insecure, tcfg, logger)
 	defer closer()
-	client := echov1connect.NewEchoServiceClient(httpClient, *addr)
+	client := core.NewEchoClient(httpClient, *addr)
 
 	// Context & cancel
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
+	ctx = core.WithRequestTimeout(ctx, tcfg.RequestTimeout)
 
 	// Timer untuk durasi maksimum (safety timeout)
 	go func() {
@@ -127,14 +168,23 @@ func main() {
 	latCh := make(chan core.Record, 1<<20)
 	jobs := make(chan struct{}, 1<<16)
 	counters := core.NewCounters()
+	labels := core.MetricLabels{Scenario: "uplink-loss", Protocol: core.ProtocolName(*useH3), RunID: tcfg.RunID}
+	if err := metrics.Serve(ctx, counters, labels, logger); err != nil {
+		log.Printf("metrics endpoint: %v", err)
+		return 1
+	}
+	resources := core.StartResourceSampler(ctx, *useH3)
+	srvStats.StartRun(tcfg.RunID, logger)
 	var reqCounter atomic.Int64
 
+	// Waited on before the summary so --tui has restored the terminal
+	var progress sync.WaitGroup
 	if !*quiet {
-		go core.ProgressPrinter(ctx, counters, logger)
+		progress.Go(func() { core.ProgressPrinter(ctx, counters, logger, live) })
 	}
 
 	// Create upload request function
-	requestFn := core.SimpleRequest(fixedUploadSize)
+	requestFn := policy.Wrap(core.SimpleRequest(load.Payload))
 
 	// Collector goroutine
 	var all []core.Record
@@ -151,8 +201,8 @@ func main() {
 
 	// Start workers
 	var wg sync.WaitGroup
-	wg.Add(fixedWorkers)
-	for i := 0; i < fixedWorkers; i++ {
+	wg.Add(load.Workers)
+	for i := 0; i < load.Workers; i++ {
 		go func(workerID int) {
 			defer wg.Done()
 			logger.Debug("Worker %d started", workerID)
@@ -166,7 +216,8 @@ func main() {
 	dispWg.Add(1)
 	go func() {
 		defer dispWg.Done()
-		uplinkLossDispatcher(ctx, jobs, logger)
+		counters.SetTargetRPS(load.RPS)
+		uplinkLossDispatcher(ctx, load, jobs, logger)
 	}()
 
 	// Start benchmark
@@ -176,27 +227,32 @@ func main() {
 	// Wait for completion
 	dispWg.Wait()
 	cancel()
+	progress.Wait()
 	wg.Wait()
 	close(latCh)
 	<-doneCol
 
 	// Calculate summary
 	mu.Lock()
-	sum := core.Summarize(all)
+	sum := window.Summarize(all)
+	sum.Meta = core.Meta(config)
+	sum.Resources = resources.Stop()
+	sum.Server = srvStats.EndRun(logger)
 	mu.Unlock()
 
 	// Print results
 	fmt.Printf("\n")
 	logger.Summary(map[string]interface{}{
-		"scenario":      "uplink_loss",
-		
+		"scenario": "uplink_loss",
+
 		"protocol":      core.ProtocolName(*useH3),
-		"workers":       fixedWorkers,
-		"upload_size":   fixedUploadSize,
-		"target_rps":    fixedTargetRPS,
-		"total_uploads": fixedWorkers * fixedTotalUploads,
+		"workers":       load.Workers,
+		"upload_size":   load.Payload,
+		"target_rps":    load.RPS,
+		"total_uploads": load.Workers * fixedTotalUploads,
 		"samples":       sum.Samples,
 		"ok_rate_%":     fmt.Sprintf("%.2f", sum.OKRatePct),
+		"timeouts":      sum.Timeouts,
 		"rps":           fmt.Sprintf("%.2f", sum.RPS),
 		"p50_ms":        fmt.Sprintf("%.6f", sum.P50ms),
 		"p90_ms":        fmt.Sprintf("%.6f", sum.P90ms),
@@ -210,28 +266,28 @@ func main() {
 	// Also log in standard format for backward compatibility
 	log.Printf("done | samples=%d ok_rate=%.2f%% rps=%.2f p50=%.6fms p90=%.6fms p95=%.6fms p99=%.6fms",
 		sum.Samples, sum.OKRatePct, sum.RPS, sum.P50ms, sum.P90ms, sum.P95ms, sum.P99ms)
+	logger.Window(sum)
+	logger.Phases(sum)
+	logger.Resilience(sum)
+	logger.ResourceUsage(sum)
+	logger.ServerCost(sum)
+	logger.ErrorClasses(sum.ErrorClasses)
 
-	// Write CSV/HTML if requested
-	if csvAbs != "" {
-		if err := core.WriteCSV(csvAbs, all, logger); err != nil {
-			log.Printf("ERROR write csv: %v", err)
-		}
-	}
-	if htmlAbs != "" {
-		if err := core.WriteHTML(htmlAbs, *label, sum, logger); err != nil {
-			log.Printf("ERROR write html: %v", err)
-		}
-	}
+	sum.Checks = thresholds.Check(sum)
+	logger.Checks(sum)
+
+	out.Write(*label, all, sum, logger)
 
 	logger.Info("Total runtime: %v", time.Since(start))
+	return core.ExitCode(sum)
 }
 
 // uplinkLossDispatcher dispatches upload jobs at constant RPS
-func uplinkLossDispatcher(ctx context.Context, jobs chan<- struct{}, logger *core.Logger) {
-	logger.Info("Uplink loss dispatcher started: target RPS=%d", fixedTargetRPS)
+func uplinkLossDispatcher(ctx context.Context, load *core.Load, jobs chan<- struct{}, logger *core.Logger) {
+	logger.Info("Uplink loss dispatcher started: target RPS=%d", load.RPS)
 
-	totalJobs := fixedWorkers * fixedTotalUploads
-	interval := time.Second / time.Duration(fixedTargetRPS)
+	totalJobs := load.Workers * fixedTotalUploads
+	interval := time.Second / time.Duration(load.RPS)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
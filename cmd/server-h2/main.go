@@ -1,25 +1,33 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"time"
 
 	"golang.org/x/net/http2"
 
+	"h3-vs-h2-k6/internal/admin"
 	"h3-vs-h2-k6/internal/echo"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 func main() {
 	var (
-		addr    = flag.String("addr", ":8444", "listen addr (TLS/TCP)")
-		cert    = flag.String("cert", "cert/dev.crt", "TLS cert")
-		key     = flag.String("key", "cert/dev.key", "TLS key")
-		verbose = flag.Bool("verbose", false, "enable verbose request logging")
+		addr      = flag.String("addr", ":8444", "listen addr (TLS/TCP)")
+		cert      = flag.String("cert", "cert/dev.crt", "TLS cert")
+		key       = flag.String("key", "cert/dev.key", "TLS key")
+		verbose   = flag.Bool("verbose", false, "enable verbose request logging")
+		adminAddr = flag.String("admin-addr", "", "plain-HTTP admin endpoint for stats, run lifecycle, log level and faults, e.g. :9444 (empty = off)")
 	)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{})
 	flag.Parse()
 
 	log.Printf("[HTTP/2] ====== SERVER STARTUP ======")
@@ -27,25 +35,47 @@ func main() {
 	log.Printf("[HTTP/2] addr=%s", *addr)
 	log.Printf("[HTTP/2] cert=%s key=%s", *cert, *key)
 	log.Printf("[HTTP/2] verbose=%v", *verbose)
+	log.Printf("[HTTP/2] admin_addr=%s", *adminAddr)
+	tcfg.LogFields("[HTTP/2]")
+	log.Printf("[HTTP/2] trace_file=%s trace_sample=%v", trc.File, trc.Sample)
 	log.Printf("[HTTP/2] =============================")
 
+	shutdownTracing, err := trc.Setup("echo-server-h2")
+	if err != nil {
+		log.Fatalf("[HTTP/2] tracing: %v", err)
+	}
+	trc.FlushOnSignal(shutdownTracing)
+
 	logLevel := echo.LogLevelNormal
 	if *verbose {
 		logLevel = echo.LogLevelVerbose
 	}
 
+	stats := admin.NewStats("HTTP/2")
+	adm := admin.NewServer(stats)
+
 	s := &http.Server{
 		Addr:    *addr,
-		Handler: echo.NewMuxWithLogging(logLevel, "HTTP/2"),
+		Handler: echo.NewMuxWithAdmin(logLevel, "HTTP/2", adm),
 		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS13,
-			NextProtos: []string{"h2"},
+			MinVersion:   tls.VersionTLS13,
+			NextProtos:   []string{"h2"},
+			KeyLogWriter: tcfg.KeyLogWriter(),
 		},
 		ReadTimeout:  60 * time.Second,
 		WriteTimeout: 60 * time.Second,
+		IdleTimeout:  tcfg.IdleTimeout,
+		HTTP2:        tcfg.HTTP2Config(),
 	}
 	http2.ConfigureServer(s, &http2.Server{})
 
+	lc := net.ListenConfig{KeepAlive: tcfg.KeepAlivePeriod, Control: tcfg.Control()}
+	ln, err := lc.Listen(context.Background(), "tcp", *addr)
+	if err != nil {
+		log.Fatalf("[HTTP/2] listen %s: %v", *addr, err)
+	}
+
+	adm.ListenAndServe(*adminAddr, "[HTTP/2]")
 	log.Printf("[HTTP/2] gRPC server listening at https://localhost%s", *addr)
-	log.Fatal(s.ListenAndServeTLS(*cert, *key))
+	log.Fatal(s.ServeTLS(stats.Wire.Listener(ln), *cert, *key))
 }
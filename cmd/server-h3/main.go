@@ -7,19 +7,27 @@ import (
 	"os"
 	"time"
 
-	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
 
+	"h3-vs-h2-k6/internal/admin"
 	"h3-vs-h2-k6/internal/echo"
+	"h3-vs-h2-k6/internal/tracing"
+	"h3-vs-h2-k6/internal/transport"
 )
 
 func main() {
 	var (
-		addr    = flag.String("addr", ":8443", "listen addr (UDP/QUIC)")
-		cert    = flag.String("cert", "cert/dev.crt", "TLS cert")
-		key     = flag.String("key", "cert/dev.key", "TLS key")
-		verbose = flag.Bool("verbose", false, "enable verbose request logging")
+		addr      = flag.String("addr", ":8443", "listen addr (UDP/QUIC)")
+		cert      = flag.String("cert", "cert/dev.crt", "TLS cert")
+		key       = flag.String("key", "cert/dev.key", "TLS key")
+		verbose   = flag.Bool("verbose", false, "enable verbose request logging")
+		adminAddr = flag.String("admin-addr", "", "plain-HTTP admin endpoint for stats, run lifecycle, log level and faults, e.g. :9443 (empty = off)")
 	)
+	trc := tracing.RegisterFlags(flag.CommandLine)
+	tcfg := transport.RegisterFlags(flag.CommandLine, transport.Config{
+		HandshakeTimeout: 10 * time.Second,
+		IdleTimeout:      15 * time.Second,
+	})
 	flag.Parse()
 
 	log.Printf("[HTTP/3] ====== SERVER STARTUP ======")
@@ -27,26 +35,51 @@ func main() {
 	log.Printf("[HTTP/3] addr=%s (UDP/QUIC)", *addr)
 	log.Printf("[HTTP/3] cert=%s key=%s", *cert, *key)
 	log.Printf("[HTTP/3] verbose=%v", *verbose)
+	log.Printf("[HTTP/3] admin_addr=%s", *adminAddr)
+	tcfg.LogFields("[HTTP/3]")
+	log.Printf("[HTTP/3] trace_file=%s trace_sample=%v", trc.File, trc.Sample)
 	log.Printf("[HTTP/3] =============================")
 
+	shutdownTracing, err := trc.Setup("echo-server-h3")
+	if err != nil {
+		log.Fatalf("[HTTP/3] tracing: %v", err)
+	}
+	trc.FlushOnSignal(shutdownTracing)
+
+	// quic-go reads the GSO/ECN toggles when the UDP socket is created
+	tcfg.ApplyQUICEnv()
+
 	logLevel := echo.LogLevelNormal
 	if *verbose {
 		logLevel = echo.LogLevelVerbose
 	}
 
+	stats := admin.NewStats("HTTP/3")
+	adm := admin.NewServer(stats)
+
+	// ListenAndServeTLS ignores TLSConfig, so load the cert here to keep
+	// MinVersion and KeyLogWriter in effect
+	certPair, err := tls.LoadX509KeyPair(*cert, *key)
+	if err != nil {
+		log.Fatalf("[HTTP/3] load cert: %v", err)
+	}
+
+	quicCfg := tcfg.QUICConfig()
+	quicCfg.Tracer = transport.ChainTracers(quicCfg.Tracer, stats.Wire.QUICTracer())
+
 	s := &http3.Server{
 		Addr:    *addr,
-		Handler: echo.NewMuxWithLogging(logLevel, "HTTP/3"),
+		Handler: echo.NewMuxWithAdmin(logLevel, "HTTP/3", adm),
 		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS13,
-			NextProtos: []string{"h3"},
-		},
-		QUICConfig: &quic.Config{
-			HandshakeIdleTimeout: 10 * time.Second,
-			MaxIdleTimeout:       15 * time.Second,
+			Certificates: []tls.Certificate{certPair},
+			MinVersion:   tls.VersionTLS13,
+			NextProtos:   []string{"h3"},
+			KeyLogWriter: tcfg.KeyLogWriter(),
 		},
+		QUICConfig: quicCfg,
 	}
 
+	adm.ListenAndServe(*adminAddr, "[HTTP/3]")
 	log.Printf("[HTTP/3] gRPC server listening at https://localhost%s", *addr)
-	log.Fatal(s.ListenAndServeTLS(*cert, *key))
+	log.Fatal(s.ListenAndServe())
 }
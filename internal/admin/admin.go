@@ -0,0 +1,275 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"h3-vs-h2-k6/internal/transport"
+)
+
+// Admin API routes
+const (
+	StatsPath    = "/admin/stats"     // GET: Snapshot since the last reset or run start
+	ResetPath    = "/admin/reset"     // POST: reset counters
+	RunStartPath = "/admin/run/start" // POST ?run_id=: reset counters and open a run
+	RunEndPath   = "/admin/run/end"   // POST ?run_id=: close the run and keep its Snapshot
+	RunsPath     = "/admin/runs/"     // GET /admin/runs/{id}: Snapshot of a run
+)
+
+// maxRuns bounds the closed-run snapshots kept in memory
+const maxRuns = 100
+
+// Stats are the server-side cost counters behind the admin endpoint
+type Stats struct {
+	Protocol    string
+	Requests    atomic.Uint64 // Echo and bulk requests handled
+	AppBytesIn  atomic.Uint64 // Payload bytes seen by handlers
+	AppBytesOut atomic.Uint64
+	Wire        transport.WireCounters
+
+	mu    sync.Mutex
+	base  Snapshot  // Raw counters at the last reset
+	reset time.Time // Time of the last reset
+	runID string    // Open run, empty when none
+}
+
+// NewStats creates counters for a server speaking protocol
+func NewStats(protocol string) *Stats {
+	return &Stats{Protocol: protocol, reset: time.Now()}
+}
+
+// Snapshot is a copy of the server counters accumulated since the last
+// reset or run start
+type Snapshot struct {
+	Protocol string  `json:"protocol"`
+	RunID    string  `json:"run_id,omitempty"`
+	ElapsedS float64 `json:"elapsed_s"` // Since the last reset or run start
+	Requests uint64  `json:"requests"`
+
+	CPUUserSeconds float64 `json:"cpu_user_seconds"`
+	CPUSysSeconds  float64 `json:"cpu_sys_seconds"`
+	Allocs         uint64  `json:"allocs"`
+	AllocBytes     uint64  `json:"alloc_bytes"`
+	GCCount        uint32  `json:"gc_count"`
+	Goroutines     int     `json:"goroutines"`   // Current, not accumulated
+	CtxSwitches    uint64  `json:"ctx_switches"` // Voluntary+involuntary, Linux only
+
+	WireBytesIn  uint64 `json:"wire_bytes_in"`
+	WireBytesOut uint64 `json:"wire_bytes_out"`
+	PacketsIn    uint64 `json:"packets_in"`
+	PacketsOut   uint64 `json:"packets_out"`
+	Conns        uint64 `json:"conns"`
+	AppBytesIn   uint64 `json:"app_bytes_in"`
+	AppBytesOut  uint64 `json:"app_bytes_out"`
+}
+
+// raw reads the process-lifetime counters
+func (s *Stats) raw() Snapshot {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	user, sys, csw := rusage()
+	return Snapshot{
+		Protocol:       s.Protocol,
+		Requests:       s.Requests.Load(),
+		CPUUserSeconds: user.Seconds(),
+		CPUSysSeconds:  sys.Seconds(),
+		Allocs:         ms.Mallocs,
+		AllocBytes:     ms.TotalAlloc,
+		GCCount:        ms.NumGC,
+		Goroutines:     runtime.NumGoroutine(),
+		CtxSwitches:    csw,
+		WireBytesIn:    s.Wire.BytesIn.Load(),
+		WireBytesOut:   s.Wire.BytesOut.Load(),
+		PacketsIn:      s.Wire.PacketsIn.Load(),
+		PacketsOut:     s.Wire.PacketsOut.Load(),
+		Conns:          s.Wire.Conns.Load(),
+		AppBytesIn:     s.AppBytesIn.Load(),
+		AppBytesOut:    s.AppBytesOut.Load(),
+	}
+}
+
+// Snapshot returns the counters accumulated since the last reset
+func (s *Stats) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshotLocked()
+}
+
+func (s *Stats) snapshotLocked() Snapshot {
+	r, b := s.raw(), s.base
+	return Snapshot{
+		Protocol:       s.Protocol,
+		RunID:          s.runID,
+		ElapsedS:       time.Since(s.reset).Seconds(),
+		Requests:       r.Requests - b.Requests,
+		CPUUserSeconds: r.CPUUserSeconds - b.CPUUserSeconds,
+		CPUSysSeconds:  r.CPUSysSeconds - b.CPUSysSeconds,
+		Allocs:         r.Allocs - b.Allocs,
+		AllocBytes:     r.AllocBytes - b.AllocBytes,
+		GCCount:        r.GCCount - b.GCCount,
+		Goroutines:     r.Goroutines,
+		CtxSwitches:    r.CtxSwitches - b.CtxSwitches,
+		WireBytesIn:    r.WireBytesIn - b.WireBytesIn,
+		WireBytesOut:   r.WireBytesOut - b.WireBytesOut,
+		PacketsIn:      r.PacketsIn - b.PacketsIn,
+		PacketsOut:     r.PacketsOut - b.PacketsOut,
+		Conns:          r.Conns - b.Conns,
+		AppBytesIn:     r.AppBytesIn - b.AppBytesIn,
+		AppBytesOut:    r.AppBytesOut - b.AppBytesOut,
+	}
+}
+
+// Reset restarts accumulation from the current counters
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetLocked()
+}
+
+func (s *Stats) resetLocked() {
+	s.base = s.raw()
+	s.reset = time.Now()
+}
+
+// Server is the plain-HTTP admin endpoint of a benchmark server
+type Server struct {
+	mux   *http.ServeMux
+	stats *Stats
+
+	mu   sync.Mutex
+	runs map[string]Snapshot // Closed runs by ID
+	ids  []string            // Closing order, for eviction
+}
+
+// NewServer creates the admin endpoint with the stats and run routes registered
+func NewServer(stats *Stats) *Server {
+	a := &Server{mux: http.NewServeMux(), stats: stats, runs: make(map[string]Snapshot)}
+	a.mux.HandleFunc("GET "+StatsPath, func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, stats.Snapshot())
+	})
+	a.mux.HandleFunc("POST "+ResetPath, func(w http.ResponseWriter, r *http.Request) {
+		stats.Reset()
+		log.Printf("[%s] admin: counters reset", stats.Protocol)
+		WriteJSON(w, stats.Snapshot())
+	})
+	a.mux.HandleFunc("POST "+RunStartPath, a.runStart)
+	a.mux.HandleFunc("POST "+RunEndPath, a.runEnd)
+	a.mux.HandleFunc("GET "+RunsPath+"{id}", a.run)
+	return a
+}
+
+// Stats returns the counters behind the endpoint
+func (a *Server) Stats() *Stats {
+	return a.stats
+}
+
+// Handle registers an additional admin route, for handlers owned by other packages
+func (a *Server) Handle(pattern string, h http.HandlerFunc) {
+	a.mux.HandleFunc(pattern, h)
+}
+
+// runStart resets counters and opens a run. Starting a run while another
+// is open closes the previous one first
+func (a *Server) runStart(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("run_id")
+	if id == "" {
+		http.Error(w, "run_id required", http.StatusBadRequest)
+		return
+	}
+	s := a.stats
+	s.mu.Lock()
+	if s.runID != "" {
+		a.keep(s.runID, s.snapshotLocked())
+		log.Printf("[%s] admin: run %s superseded by %s", s.Protocol, s.runID, id)
+	}
+	s.resetLocked()
+	s.runID = id
+	snap := s.snapshotLocked()
+	s.mu.Unlock()
+
+	log.Printf("[%s] admin: run %s started", s.Protocol, id)
+	WriteJSON(w, snap)
+}
+
+// runEnd closes the open run and returns its snapshot
+func (a *Server) runEnd(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("run_id")
+	s := a.stats
+	s.mu.Lock()
+	if s.runID == "" || (id != "" && id != s.runID) {
+		open := s.runID
+		s.mu.Unlock()
+		http.Error(w, "run not open: "+id+" (open: "+open+")", http.StatusConflict)
+		return
+	}
+	id = s.runID
+	snap := s.snapshotLocked()
+	s.runID = ""
+	a.keep(id, snap)
+	s.mu.Unlock()
+
+	log.Printf("[%s] admin: run %s ended: requests=%d cpu=%.3fs", s.Protocol, id, snap.Requests, snap.CPUUserSeconds+snap.CPUSysSeconds)
+	WriteJSON(w, snap)
+}
+
+// run returns the snapshot of a closed run, or the live one of the open run
+func (a *Server) run(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s := a.stats
+	s.mu.Lock()
+	if s.runID == id {
+		snap := s.snapshotLocked()
+		s.mu.Unlock()
+		WriteJSON(w, snap)
+		return
+	}
+	s.mu.Unlock()
+
+	a.mu.Lock()
+	snap, ok := a.runs[id]
+	a.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown run "+id, http.StatusNotFound)
+		return
+	}
+	WriteJSON(w, snap)
+}
+
+func (a *Server) keep(id string, snap Snapshot) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.runs[id]; !ok {
+		a.ids = append(a.ids, id)
+	}
+	a.runs[id] = snap
+	for len(a.ids) > maxRuns {
+		delete(a.runs, a.ids[0])
+		a.ids = a.ids[1:]
+	}
+}
+
+// ListenAndServe serves the admin endpoint in the background (empty addr = off)
+func (a *Server) ListenAndServe(addr, prefix string) {
+	if addr == "" {
+		return
+	}
+	srv := &http.Server{Addr: addr, Handler: a.mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("%s admin endpoint: %v", prefix, err)
+		}
+	}()
+	log.Printf("%s admin endpoint listening at http://localhost%s/admin/", prefix, addr)
+}
+
+// WriteJSON writes v as a JSON response
+func WriteJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package admin
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// rusage returns CPU time and context switches of the process
+func rusage() (user, sys time.Duration, ctxSwitches uint64) {
+	var ru unix.Rusage
+	if err := unix.Getrusage(unix.RUSAGE_SELF, &ru); err != nil {
+		return 0, 0, 0
+	}
+	return time.Duration(ru.Utime.Nano()), time.Duration(ru.Stime.Nano()), uint64(ru.Nvcsw + ru.Nivcsw)
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package admin
+
+import "time"
+
+// rusage is only measured on Linux
+func rusage() (user, sys time.Duration, ctxSwitches uint64) { return 0, 0, 0 }
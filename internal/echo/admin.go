@@ -0,0 +1,59 @@
+package echo
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"h3-vs-h2-k6/internal/admin"
+)
+
+// Admin routes owned by the echo service
+const (
+	LogLevelPath = "/admin/loglevel" // GET, PUT {"level":"verbose"}
+	FaultsPath   = "/admin/faults"   // GET, PUT FaultConfig
+)
+
+type logLevelBody struct {
+	Level string `json:"level"`
+}
+
+// registerAdmin adds the log level and fault injection routes to the admin endpoint
+func (s *svc) registerAdmin(adm *admin.Server) {
+	adm.Handle("GET "+LogLevelPath, func(w http.ResponseWriter, r *http.Request) {
+		admin.WriteJSON(w, logLevelBody{Level: s.level().String()})
+	})
+	adm.Handle("PUT "+LogLevelPath, func(w http.ResponseWriter, r *http.Request) {
+		var body logLevelBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, err := ParseLogLevel(body.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logLevel.Store(int32(level))
+		log.Printf("[%s] admin: log level set to %s", s.protocol, level)
+		admin.WriteJSON(w, logLevelBody{Level: level.String()})
+	})
+	adm.Handle("GET "+FaultsPath, func(w http.ResponseWriter, r *http.Request) {
+		admin.WriteJSON(w, s.faults.get())
+	})
+	adm.Handle("PUT "+FaultsPath, func(w http.ResponseWriter, r *http.Request) {
+		var cfg FaultConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := cfg.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.faults.set(cfg)
+		log.Printf("[%s] admin: faults enabled=%v error_rate=%v delay_ms=%d jitter_ms=%d",
+			s.protocol, cfg.Enabled, cfg.ErrorRate, cfg.DelayMs, cfg.JitterMs)
+		admin.WriteJSON(w, cfg)
+	})
+}
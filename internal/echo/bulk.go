@@ -0,0 +1,88 @@
+package echo
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// BulkPath serves raw bulk transfers outside of Connect framing
+const BulkPath = "/bulk"
+
+const (
+	bulkChunkSize = 64 * 1024
+	bulkMaxSize   = 8 << 30 // 8GB
+)
+
+// bulk streams a response body of ?size=N bytes (GET) or drains the request body (POST)
+func (s *svc) bulk(w http.ResponseWriter, r *http.Request) {
+	// Large transfers may outlive the server read/write timeouts
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	t0 := time.Now()
+	s.stats.Requests.Add(1)
+	if err := s.faults.inject(r.Context()); err != nil {
+		if s.level() >= LogLevelMinimal {
+			log.Printf("[%s] BULK %s: %v", s.protocol, r.Method, err)
+		}
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		size, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64)
+		if err != nil || size < 0 || size > bulkMaxSize {
+			http.Error(w, "invalid size", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+
+		chunk := make([]byte, bulkChunkSize)
+		var sent int64
+		for sent < size {
+			n := int64(len(chunk))
+			if size-sent < n {
+				n = size - sent
+			}
+			if _, err := w.Write(chunk[:n]); err != nil {
+				if s.level() >= LogLevelMinimal {
+					log.Printf("[%s] BULK download aborted after %d/%d bytes: %v", s.protocol, sent, size, err)
+				}
+				return
+			}
+			sent += n
+			s.stats.AppBytesOut.Add(uint64(n))
+		}
+		if s.level() >= LogLevelVerbose {
+			log.Printf("[%s] BULK download: %d bytes in %v", s.protocol, sent, time.Since(t0))
+		}
+
+	case http.MethodPost, http.MethodPut:
+		received, err := io.Copy(io.Discard, r.Body)
+		s.stats.AppBytesIn.Add(uint64(received))
+		if err != nil {
+			if s.level() >= LogLevelMinimal {
+				log.Printf("[%s] BULK upload aborted after %d bytes: %v", s.protocol, received, err)
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("x-bulk-received", strconv.FormatInt(received, 10))
+		fmt.Fprintf(w, "received=%d\n", received)
+		if s.level() >= LogLevelVerbose {
+			log.Printf("[%s] BULK upload: %d bytes in %v", s.protocol, received, time.Since(t0))
+		}
+
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
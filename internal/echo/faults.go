@@ -0,0 +1,75 @@
+package echo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned by handlers failed by fault injection
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultConfig controls fault injection on echo and bulk requests
+type FaultConfig struct {
+	Enabled   bool    `json:"enabled"`
+	ErrorRate float64 `json:"error_rate"` // Fraction of requests failed, 0..1
+	DelayMs   int     `json:"delay_ms"`   // Extra latency added to every request
+	JitterMs  int     `json:"jitter_ms"`  // Uniform random extra latency on top of DelayMs
+}
+
+func (c FaultConfig) validate() error {
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be in [0,1], got %v", c.ErrorRate)
+	}
+	if c.DelayMs < 0 || c.JitterMs < 0 {
+		return fmt.Errorf("delay_ms and jitter_ms must be >= 0")
+	}
+	return nil
+}
+
+// faults holds the current FaultConfig, switchable at runtime
+type faults struct {
+	mu  sync.RWMutex
+	cfg FaultConfig
+}
+
+func (f *faults) get() FaultConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.cfg
+}
+
+func (f *faults) set(cfg FaultConfig) {
+	f.mu.Lock()
+	f.cfg = cfg
+	f.mu.Unlock()
+}
+
+// inject applies the configured delay, then fails the request with
+// ErrInjectedFault at the configured rate
+func (f *faults) inject(ctx context.Context) error {
+	cfg := f.get()
+	if !cfg.Enabled {
+		return nil
+	}
+	delay := time.Duration(cfg.DelayMs) * time.Millisecond
+	if cfg.JitterMs > 0 {
+		delay += time.Duration(rand.Int64N(int64(cfg.JitterMs)+1)) * time.Millisecond
+	}
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+	if cfg.ErrorRate > 0 && rand.Float64() < cfg.ErrorRate {
+		return ErrInjectedFault
+	}
+	return nil
+}
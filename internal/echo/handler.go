@@ -2,6 +2,7 @@ package echo
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
@@ -9,9 +10,13 @@ import (
 	"time"
 
 	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	echov1 "h3-vs-h2-k6/echo/v1"
 	"h3-vs-h2-k6/echo/v1/echov1connect"
+	"h3-vs-h2-k6/internal/admin"
+	"h3-vs-h2-k6/internal/tracing"
 )
 
 const HeaderBloatKey = "x-meta-bloat"
@@ -26,11 +31,32 @@ const (
 	LogLevelVerbose                 // Every request
 )
 
+var logLevelNames = []string{"quiet", "minimal", "normal", "verbose"}
+
+func (l LogLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return "LogLevel(" + strconv.Itoa(int(l)) + ")"
+	}
+	return logLevelNames[l]
+}
+
+// ParseLogLevel parses quiet, minimal, normal or verbose
+func ParseLogLevel(s string) (LogLevel, error) {
+	for i, name := range logLevelNames {
+		if s == name {
+			return LogLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (quiet|minimal|normal|verbose)", s)
+}
+
 type svc struct {
-	logLevel    LogLevel
+	logLevel    atomic.Int32 // LogLevel, switchable through the admin API
 	reqCount    atomic.Int64
 	protocol    string
 	lastLogTime atomic.Int64
+	stats       *admin.Stats
+	faults      faults
 }
 
 // NewMux creates a new HTTP mux with echo service handler
@@ -40,17 +66,35 @@ func NewMux() *http.ServeMux {
 
 // NewMuxWithLogging creates a new HTTP mux with configurable logging
 func NewMuxWithLogging(level LogLevel, protocol string) *http.ServeMux {
+	return NewMuxWithAdmin(level, protocol, admin.NewServer(admin.NewStats(protocol)))
+}
+
+// NewMuxWithAdmin creates a new HTTP mux that counts requests and payload
+// bytes into the admin stats and exposes log level and fault injection
+// controls on the admin endpoint
+func NewMuxWithAdmin(level LogLevel, protocol string, adm *admin.Server) *http.ServeMux {
 	mux := http.NewServeMux()
-	s := &svc{logLevel: level, protocol: protocol}
-	path, h := echov1connect.NewEchoServiceHandler(s)
+	s := &svc{protocol: protocol, stats: adm.Stats()}
+	s.logLevel.Store(int32(level))
+	s.registerAdmin(adm)
+	path, h := echov1connect.NewEchoServiceHandler(s, connect.WithInterceptors(tracing.ServerInterceptor()))
 	mux.Handle(path, h)
+	mux.HandleFunc(BulkPath, s.bulk)
 	log.Printf("[%s] Echo service handler registered at %s", protocol, path)
+	log.Printf("[%s] Bulk transfer handler registered at %s", protocol, BulkPath)
 	return mux
 }
 
+func (s *svc) level() LogLevel {
+	return LogLevel(s.logLevel.Load())
+}
+
 func (s *svc) Unary(ctx context.Context, req *connect.Request[echov1.EchoRequest]) (*connect.Response[echov1.EchoResponse], error) {
 	reqID := s.reqCount.Add(1)
 	t0 := time.Now()
+	s.stats.Requests.Add(1)
+	s.stats.AppBytesIn.Add(uint64(len(req.Msg.GetPayload())))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("bench.server_request_id", reqID))
 
 	// Count header bloat
 	totalBloatSize := 0
@@ -70,11 +114,18 @@ func (s *svc) Unary(ctx context.Context, req *connect.Request[echov1.EchoRequest
 	}
 
 	// Log request if verbose
-	if s.logLevel >= LogLevelVerbose {
+	if s.level() >= LogLevelVerbose {
 		log.Printf("[%s] REQ #%d: msg=%q payload=%d bytes, bloat_headers=%d total_bloat=%d bytes",
 			s.protocol, reqID, req.Msg.GetMessage(), len(req.Msg.GetPayload()), bloatHeaderCount, totalBloatSize)
 	}
 
+	if err := s.faults.inject(ctx); err != nil {
+		if s.level() >= LogLevelMinimal {
+			log.Printf("[%s] REQ #%d: %v", s.protocol, reqID, err)
+		}
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+
 	// Build response
 	resp := connect.NewResponse(&echov1.EchoResponse{
 		Message: req.Msg.GetMessage(),
@@ -82,6 +133,7 @@ func (s *svc) Unary(ctx context.Context, req *connect.Request[echov1.EchoRequest
 	})
 	resp.Header().Set("server-recv-bloat-len", strconv.Itoa(totalBloatSize))
 	resp.Header().Set("x-request-id", strconv.FormatInt(reqID, 10))
+	s.stats.AppBytesOut.Add(uint64(len(req.Msg.GetPayload())))
 
 	// Simulate light work
 	time.Sleep(1 * time.Millisecond)
@@ -89,12 +141,12 @@ func (s *svc) Unary(ctx context.Context, req *connect.Request[echov1.EchoRequest
 	latency := time.Since(t0)
 
 	// Log response if verbose
-	if s.logLevel >= LogLevelVerbose {
+	if s.level() >= LogLevelVerbose {
 		log.Printf("[%s] RES #%d: latency=%v", s.protocol, reqID, latency)
 	}
 
 	// Log summary every second if normal level
-	if s.logLevel == LogLevelNormal {
+	if s.level() == LogLevelNormal {
 		now := time.Now().Unix()
 		last := s.lastLogTime.Load()
 		if now > last && s.lastLogTime.CompareAndSwap(last, now) {
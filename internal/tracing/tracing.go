@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of all spans in this repo
+const TracerName = "h3-vs-h2-k6"
+
+// Config holds tracing settings shared by servers and clients.
+// Tracing is off unless File is set
+type Config struct {
+	File   string  // Span output file, one JSON span per line
+	Sample float64 // Fraction of root traces sampled; servers follow the caller's decision
+}
+
+// RegisterFlags registers tracing flags on fs
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	c := &Config{}
+	fs.StringVar(&c.File, "trace-file", "", "write OpenTelemetry spans as JSON lines to this file (empty = tracing off)")
+	fs.Float64Var(&c.Sample, "trace-sample", 1.0, "fraction of requests traced (servers follow the client's traceparent)")
+	return c
+}
+
+// Fields returns the tracing settings for startup logs and results
+func (c *Config) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"trace_file":   c.File,
+		"trace_sample": c.Sample,
+	}
+}
+
+// Setup installs the global tracer provider and W3C trace-context
+// propagator. The returned shutdown flushes pending spans and closes the file
+func (c *Config) Setup(service string) (func(context.Context) error, error) {
+	if c.File == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.File), 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir %s: %w", filepath.Dir(c.File), err)
+	}
+	f, err := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.Sample))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), f.Close())
+	}, nil
+}
+
+// Tracer returns the repo tracer from the global provider (no-op when tracing is off)
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// ServerInterceptor extracts the caller's trace context and wraps each
+// unary handler call in a server span
+func ServerInterceptor() connect.Interceptor {
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header()))
+			ctx, span := Tracer().Start(ctx, req.Spec().Procedure,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attribute.String("rpc.system", "connect_rpc"), attribute.String("rpc.connect.protocol", req.Peer().Protocol)))
+			defer span.End()
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return resp, err
+		}
+	})
+}
+
+// FlushOnSignal runs shutdown and exits on SIGINT/SIGTERM, for servers
+// that otherwise never return from Serve. No-op when tracing is off
+func (c *Config) FlushOnSignal(shutdown func(context.Context) error) {
+	if c.File == "" {
+		return
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = shutdown(ctx)
+		os.Exit(0)
+	}()
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+	"github.com/quic-go/quic-go/qlog"
+)
+
+// capture holds the shared qlog/keylog state of a Config, so every
+// client or server built from the same Config writes to the same files
+type capture struct {
+	keylogOnce sync.Once
+	keylog     io.Writer
+
+	qlogOnce sync.Once
+	qlogOK   bool
+	qlogged  atomic.Int64
+}
+
+// NewRunID returns an identifier used to name capture files of one run
+func NewRunID() string {
+	return fmt.Sprintf("%s-%d", time.Now().Format("20060102T150405"), os.Getpid())
+}
+
+// KeyLogWriter returns the NSS key log writer for tls.Config.KeyLogWriter,
+// or nil when --keylog is not set
+func (c *Config) KeyLogWriter() io.Writer {
+	if c.KeyLogFile == "" || c.capture == nil {
+		return nil
+	}
+	c.capture.keylogOnce.Do(func() {
+		f, err := os.OpenFile(c.KeyLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			log.Printf("[transport] open keylog %s: %v (keylog disabled)", c.KeyLogFile, err)
+			return
+		}
+		log.Printf("[transport] writing TLS key log to %s", c.KeyLogFile)
+		c.capture.keylog = f
+	})
+	return c.capture.keylog
+}
+
+// qlogTracer returns the quic.Config tracer writing sampled qlog files,
+// or nil when --qlog-dir is not set
+func (c *Config) qlogTracer() func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+	if c.QlogDir == "" || c.capture == nil {
+		return nil
+	}
+	c.capture.qlogOnce.Do(func() {
+		if err := os.MkdirAll(c.QlogDir, 0o755); err != nil {
+			log.Printf("[transport] create qlog dir %s: %v (qlog disabled)", c.QlogDir, err)
+			return
+		}
+		c.capture.qlogOK = true
+	})
+	if !c.capture.qlogOK {
+		return nil
+	}
+	return func(_ context.Context, p logging.Perspective, connID quic.ConnectionID) *logging.ConnectionTracer {
+		// Per-connection sampling, capped so 1000 workers don't flood the disk
+		if c.QlogSample < 1 && rand.Float64() >= c.QlogSample {
+			return nil
+		}
+		if c.QlogMax > 0 && c.capture.qlogged.Add(1) > int64(c.QlogMax) {
+			return nil
+		}
+		role := "server"
+		if p == logging.PerspectiveClient {
+			role = "client"
+		}
+		path := filepath.Join(c.QlogDir, fmt.Sprintf("%s_%s_%s.sqlog", c.RunID, role, connID))
+		f, err := os.Create(path)
+		if err != nil {
+			log.Printf("[transport] create qlog %s: %v", path, err)
+			return nil
+		}
+		return qlog.NewConnectionTracer(&bufferedFile{Writer: bufio.NewWriter(f), f: f}, p, connID)
+	}
+}
+
+// bufferedFile flushes buffered qlog events when quic-go closes the trace
+type bufferedFile struct {
+	*bufio.Writer
+	f *os.File
+}
+
+func (b *bufferedFile) Close() error {
+	if err := b.Flush(); err != nil {
+		b.f.Close()
+		return err
+	}
+	return b.f.Close()
+}
@@ -0,0 +1,254 @@
+package transport
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// quic-go default initial receive windows (auto-tuned up to the max windows).
+// The connection window is 1.5x the stream window (protocol.DefaultInitialMaxData)
+const (
+	quicDefaultInitialStreamWindow     = 512 << 10
+	quicDefaultInitialConnectionWindow = quicDefaultInitialStreamWindow * 3 / 2
+)
+
+// Initial packet sizes quic-go uses as given; it clamps anything else
+// (protocol.MinInitialPacketSize, protocol.MaxPacketBufferSize)
+const (
+	MinInitialPacketSize = 1200
+	MaxInitialPacketSize = 1452
+)
+
+// Config holds transport tuning shared by servers and clients.
+// Zero values keep the library defaults.
+type Config struct {
+	// Connection lifecycle
+	IdleTimeout      time.Duration // QUIC MaxIdleTimeout, HTTP/2 idle connection timeout
+	HandshakeTimeout time.Duration // QUIC HandshakeIdleTimeout, TLS handshake timeout over TCP
+	KeepAlivePeriod  time.Duration // QUIC keep-alive PINGs, TCP keep-alive probes
+
+	// Client timeouts (ignored by servers)
+	ConnectTimeout time.Duration // TCP dial and TLS handshake, or QUIC handshake
+	RequestTimeout time.Duration // Deadline for a single request, including connection setup
+
+	// Stream limits
+	MaxStreams int // QUIC MaxIncomingStreams, HTTP/2 MaxConcurrentStreams
+
+	// Flow control
+	QUICMaxStreamReceiveWindow     uint64 // quic-go MaxStreamReceiveWindow (bytes)
+	QUICMaxConnectionReceiveWindow uint64 // quic-go MaxConnectionReceiveWindow (bytes)
+	H2StreamReceiveWindow          int    // HTTP/2 per-stream receive window (bytes)
+	H2ConnReceiveWindow            int    // HTTP/2 per-connection receive window (bytes)
+
+	// Header compression. quic-go's QPACK uses the static table only and
+	// advertises a zero dynamic table capacity, so only HPACK is tunable
+	H2EncoderTableSize int // HPACK encoder dynamic table limit (bytes), capped by the peer's decoder size
+	H2DecoderTableSize int // HPACK decoder dynamic table size advertised in SETTINGS_HEADER_TABLE_SIZE (bytes)
+
+	// QUIC path
+	InitialPacketSize       uint // QUIC initial packet size (bytes)
+	DisablePathMTUDiscovery bool // Disable QUIC DPLPMTUD
+
+	// HTTP/2 health checks
+	H2ReadIdleTimeout time.Duration // Send a PING after this much read inactivity
+	H2PingTimeout     time.Duration // Close the connection if the PING is not answered
+
+	// Congestion control and UDP offloads
+	TCPCongestion  string // TCP_CONGESTION algorithm for HTTP/2 sockets (empty = system default)
+	QUICDisableGSO bool   // Sets QUIC_GO_DISABLE_GSO for quic-go
+	QUICDisableECN bool   // Sets QUIC_GO_DISABLE_ECN for quic-go
+
+	// Protocol capture for offline analysis
+	RunID      string  // Names capture files of one run
+	QlogDir    string  // Directory for per-connection qlog files (empty = disabled)
+	QlogSample float64 // Fraction of QUIC connections traced
+	QlogMax    int     // Max qlog files per process (0 = unlimited)
+	KeyLogFile string  // NSS key log file for decrypting pcaps (empty = disabled)
+
+	capture *capture // Set by RegisterFlags
+}
+
+// RegisterFlags registers transport tuning flags on fs, using def for default values
+func RegisterFlags(fs *flag.FlagSet, def Config) *Config {
+	c := &Config{capture: &capture{}}
+	fs.DurationVar(&c.IdleTimeout, "idle-timeout", def.IdleTimeout, "connection idle timeout (0 = library default)")
+	fs.DurationVar(&c.HandshakeTimeout, "handshake-timeout", def.HandshakeTimeout, "QUIC/TLS handshake timeout (0 = library default)")
+	fs.DurationVar(&c.KeepAlivePeriod, "keepalive", def.KeepAlivePeriod, "QUIC keep-alive / TCP keep-alive period (0 = library default)")
+	fs.DurationVar(&c.ConnectTimeout, "connect-timeout", def.ConnectTimeout, "client connection setup timeout: TCP+TLS or QUIC handshake (0 = none)")
+	fs.DurationVar(&c.RequestTimeout, "request-timeout", def.RequestTimeout, "client per-request deadline, timed-out requests are recorded as timeouts (0 = none)")
+	fs.IntVar(&c.MaxStreams, "max-streams", def.MaxStreams, "max concurrent incoming streams per connection (0 = library default)")
+	fs.Uint64Var(&c.QUICMaxStreamReceiveWindow, "quic-max-stream-window", def.QUICMaxStreamReceiveWindow, "QUIC max stream receive window in bytes (0 = quic-go default)")
+	fs.Uint64Var(&c.QUICMaxConnectionReceiveWindow, "quic-max-conn-window", def.QUICMaxConnectionReceiveWindow, "QUIC max connection receive window in bytes (0 = quic-go default)")
+	fs.IntVar(&c.H2StreamReceiveWindow, "h2-stream-window", def.H2StreamReceiveWindow, "HTTP/2 stream receive window in bytes (0 = x/net default)")
+	fs.IntVar(&c.H2ConnReceiveWindow, "h2-conn-window", def.H2ConnReceiveWindow, "HTTP/2 connection receive window in bytes (0 = x/net default)")
+	fs.IntVar(&c.H2EncoderTableSize, "h2-encoder-table-size", def.H2EncoderTableSize, "HTTP/2 HPACK encoder dynamic table limit in bytes, capped by the peer's decoder table; 1 disables indexing (0 = x/net default 4096)")
+	fs.IntVar(&c.H2DecoderTableSize, "h2-decoder-table-size", def.H2DecoderTableSize, "HTTP/2 HPACK decoder dynamic table size advertised to the peer in bytes (0 = x/net default 4096)")
+	c.InitialPacketSize = def.InitialPacketSize
+	fs.Var(packetSizeFlag{&c.InitialPacketSize}, "initial-packet-size", fmt.Sprintf("QUIC initial packet size in bytes, %d..%d (0 = quic-go default)", MinInitialPacketSize, MaxInitialPacketSize))
+	fs.BoolVar(&c.DisablePathMTUDiscovery, "disable-pmtud", def.DisablePathMTUDiscovery, "disable QUIC path MTU discovery")
+	fs.DurationVar(&c.H2ReadIdleTimeout, "h2-read-idle-timeout", def.H2ReadIdleTimeout, "send HTTP/2 PING after this much read inactivity (0 = disabled)")
+	fs.DurationVar(&c.H2PingTimeout, "h2-ping-timeout", def.H2PingTimeout, "close HTTP/2 connection if PING is unanswered (0 = x/net default)")
+	fs.StringVar(&c.TCPCongestion, "tcp-cc", def.TCPCongestion, "TCP congestion control for HTTP/2 sockets, e.g. cubic|bbr (empty = system default)")
+	fs.BoolVar(&c.QUICDisableGSO, "quic-disable-gso", def.QUICDisableGSO || envBool("QUIC_GO_DISABLE_GSO"), "disable UDP GSO in quic-go")
+	fs.BoolVar(&c.QUICDisableECN, "quic-disable-ecn", def.QUICDisableECN || envBool("QUIC_GO_DISABLE_ECN"), "disable ECN in quic-go")
+	fs.StringVar(&c.RunID, "run-id", NewRunID(), "run identifier used to name capture files")
+	fs.StringVar(&c.QlogDir, "qlog-dir", def.QlogDir, "write sampled per-connection qlog files to this directory (HTTP/3)")
+	fs.Float64Var(&c.QlogSample, "qlog-sample", orFloat(def.QlogSample, 0.1), "fraction of QUIC connections written to --qlog-dir (0..1)")
+	fs.IntVar(&c.QlogMax, "qlog-max", orInt(def.QlogMax, 100), "max qlog files per process (0 = unlimited)")
+	fs.StringVar(&c.KeyLogFile, "keylog", def.KeyLogFile, "append TLS secrets in NSS key log format to this file (decrypt pcaps)")
+	return c
+}
+
+// ApplyQUICEnv exports the GSO/ECN toggles to the environment variables read by quic-go.
+// quic-go reads them when a UDP socket is set up, so call this before listening or dialing
+func (c *Config) ApplyQUICEnv() {
+	os.Setenv("QUIC_GO_DISABLE_GSO", strconv.FormatBool(c.QUICDisableGSO))
+	os.Setenv("QUIC_GO_DISABLE_ECN", strconv.FormatBool(c.QUICDisableECN))
+}
+
+// packetSizeFlag is --initial-packet-size: 0 or a size quic-go uses unclamped
+type packetSizeFlag struct{ v *uint }
+
+func (f packetSizeFlag) String() string {
+	if f.v == nil {
+		return "0"
+	}
+	return strconv.FormatUint(uint64(*f.v), 10)
+}
+
+func (f packetSizeFlag) Set(s string) error {
+	n, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return err
+	}
+	if n != 0 && (n < MinInitialPacketSize || n > MaxInitialPacketSize) {
+		return fmt.Errorf("must be 0 or %d..%d bytes, got %d", MinInitialPacketSize, MaxInitialPacketSize, n)
+	}
+	*f.v = uint(n)
+	return nil
+}
+
+// orFloat returns v, or def when v is zero
+func orFloat(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// orInt returns v, or def when v is zero
+func orInt(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// envBool reports whether the environment variable is set to a true value
+func envBool(name string) bool {
+	v, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && v
+}
+
+// QUICConfig builds a quic.Config from the tuning values
+func (c *Config) QUICConfig() *quic.Config {
+	qc := &quic.Config{
+		HandshakeIdleTimeout:       c.HandshakeTimeout,
+		MaxIdleTimeout:             c.IdleTimeout,
+		KeepAlivePeriod:            c.KeepAlivePeriod,
+		MaxIncomingStreams:         int64(c.MaxStreams),
+		MaxStreamReceiveWindow:     c.QUICMaxStreamReceiveWindow,
+		MaxConnectionReceiveWindow: c.QUICMaxConnectionReceiveWindow,
+		InitialPacketSize:          uint16(c.InitialPacketSize),
+		DisablePathMTUDiscovery:    c.DisablePathMTUDiscovery,
+		Tracer:                     c.qlogTracer(),
+	}
+	// Keep the initial windows within the configured maximum
+	if qc.MaxStreamReceiveWindow > 0 && qc.MaxStreamReceiveWindow < quicDefaultInitialStreamWindow {
+		qc.InitialStreamReceiveWindow = qc.MaxStreamReceiveWindow
+	}
+	if qc.MaxConnectionReceiveWindow > 0 && qc.MaxConnectionReceiveWindow < quicDefaultInitialConnectionWindow {
+		qc.InitialConnectionReceiveWindow = qc.MaxConnectionReceiveWindow
+	}
+	return qc
+}
+
+// HTTP2Config builds the net/http HTTP/2 config honored by both
+// http.Server and x/net/http2 transports
+func (c *Config) HTTP2Config() *http.HTTP2Config {
+	return &http.HTTP2Config{
+		MaxConcurrentStreams:          c.MaxStreams,
+		MaxReceiveBufferPerStream:     c.H2StreamReceiveWindow,
+		MaxReceiveBufferPerConnection: c.H2ConnReceiveWindow,
+		SendPingTimeout:               c.H2ReadIdleTimeout,
+		PingTimeout:                   c.H2PingTimeout,
+		MaxEncoderHeaderTableSize:     c.H2EncoderTableSize,
+		MaxDecoderHeaderTableSize:     c.H2DecoderTableSize,
+	}
+}
+
+// HPACKDefaultTableSize is the HPACK dynamic table size x/net uses when unset
+const HPACKDefaultTableSize = 4096
+
+// QPACKTableCapacity is the QPACK dynamic table capacity quic-go supports
+const QPACKTableCapacity = 0
+
+// HPACKEncoderTableSize returns the HPACK encoder table size in effect,
+// assuming the peer's decoder table is at least as large
+func (c *Config) HPACKEncoderTableSize() int {
+	if c.H2EncoderTableSize <= 0 {
+		return HPACKDefaultTableSize
+	}
+	return c.H2EncoderTableSize
+}
+
+// Fields returns the tuning values for startup logs and results
+func (c *Config) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"idle_timeout":           c.IdleTimeout,
+		"handshake_timeout":      c.HandshakeTimeout,
+		"keepalive":              c.KeepAlivePeriod,
+		"connect_timeout":        c.ConnectTimeout,
+		"request_timeout":        c.RequestTimeout,
+		"max_streams":            c.MaxStreams,
+		"quic_max_stream_window": c.QUICMaxStreamReceiveWindow,
+		"quic_max_conn_window":   c.QUICMaxConnectionReceiveWindow,
+		"h2_stream_window":       c.H2StreamReceiveWindow,
+		"h2_conn_window":         c.H2ConnReceiveWindow,
+		"h2_encoder_table_size":  c.H2EncoderTableSize,
+		"h2_decoder_table_size":  c.H2DecoderTableSize,
+		"qpack_table_capacity":   QPACKTableCapacity,
+		"initial_packet_size":    c.InitialPacketSize,
+		"disable_pmtud":          c.DisablePathMTUDiscovery,
+		"h2_read_idle_timeout":   c.H2ReadIdleTimeout,
+		"h2_ping_timeout":        c.H2PingTimeout,
+		"tcp_cc":                 EffectiveTCPCongestion(c.TCPCongestion),
+		"quic_gso":               quicGSOStatus(c.QUICDisableGSO),
+		"quic_ecn":               quicECNStatus(c.QUICDisableECN),
+		"run_id":                 c.RunID,
+		"qlog_dir":               c.QlogDir,
+		"qlog_sample":            c.QlogSample,
+		"keylog":                 c.KeyLogFile,
+	}
+}
+
+// LogFields prints the tuning values in a stable order, one per line
+func (c *Config) LogFields(prefix string) {
+	fields := c.Fields()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		log.Printf("%s %s=%v", prefix, k, fields[k])
+	}
+}
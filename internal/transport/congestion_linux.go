@@ -0,0 +1,133 @@
+//go:build linux
+
+package transport
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var ccWarnOnce sync.Once
+
+// Control returns a socket control hook that applies TCP_CONGESTION to
+// HTTP/2 sockets, or nil when the system default is used.
+// Algorithms the process is not permitted to use are logged once and skipped
+func (c *Config) Control() func(network, address string, rc syscall.RawConn) error {
+	if c.TCPCongestion == "" {
+		return nil
+	}
+	cc := c.TCPCongestion
+	return func(network, address string, rc syscall.RawConn) error {
+		if !strings.HasPrefix(network, "tcp") {
+			return nil
+		}
+		var serr error
+		if err := rc.Control(func(fd uintptr) {
+			serr = unix.SetsockoptString(int(fd), unix.IPPROTO_TCP, unix.TCP_CONGESTION, cc)
+		}); err != nil {
+			return err
+		}
+		if serr != nil {
+			ccWarnOnce.Do(func() {
+				log.Printf("[transport] TCP_CONGESTION=%s not permitted: %v (allowed: %s), using system default",
+					cc, serr, readProc("/proc/sys/net/ipv4/tcp_allowed_congestion_control"))
+			})
+		}
+		return nil
+	}
+}
+
+// EffectiveTCPCongestion reports the algorithm a new TCP socket ends up with
+// after requesting cc, e.g. "bbr" or "cubic (requested bbr: operation not permitted)"
+func EffectiveTCPCongestion(cc string) string {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, 0)
+	if err != nil {
+		return "unknown"
+	}
+	defer unix.Close(fd)
+
+	var serr error
+	if cc != "" {
+		serr = unix.SetsockoptString(fd, unix.IPPROTO_TCP, unix.TCP_CONGESTION, cc)
+	}
+	got, err := unix.GetsockoptString(fd, unix.IPPROTO_TCP, unix.TCP_CONGESTION)
+	if err != nil {
+		return "unknown"
+	}
+	if serr != nil {
+		return fmt.Sprintf("%s (requested %s: %v)", got, cc, serr)
+	}
+	return got
+}
+
+// quicGSOStatus mirrors quic-go's check: GSO is used when not disabled,
+// on kernel 5+ and when the kernel accepts UDP_SEGMENT
+func quicGSOStatus(disabled bool) string {
+	if disabled {
+		return "disabled"
+	}
+	if kernelMajor() < 5 {
+		return "unsupported"
+	}
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM, 0)
+	if err != nil {
+		return "unknown"
+	}
+	defer unix.Close(fd)
+	if _, err := unix.GetsockoptInt(fd, unix.IPPROTO_UDP, unix.UDP_SEGMENT); err != nil {
+		return "unsupported"
+	}
+	return "enabled"
+}
+
+// quicECNStatus mirrors quic-go's check: ECN is used when not disabled,
+// on kernel 5+ and when a UDP socket accepts IP_RECVTOS or IPV6_RECVTCLASS
+func quicECNStatus(disabled bool) string {
+	if disabled {
+		return "disabled"
+	}
+	if kernelMajor() < 5 {
+		return "unsupported"
+	}
+	fd, err := unix.Socket(unix.AF_INET6, unix.SOCK_DGRAM, 0)
+	if err != nil {
+		return "unknown"
+	}
+	defer unix.Close(fd)
+	errV4 := unix.SetsockoptInt(fd, unix.IPPROTO_IP, unix.IP_RECVTOS, 1)
+	errV6 := unix.SetsockoptInt(fd, unix.IPPROTO_IPV6, unix.IPV6_RECVTCLASS, 1)
+	if errV4 != nil && errV6 != nil {
+		return "unsupported"
+	}
+	return "enabled"
+}
+
+// kernelMajor returns the major version of the running kernel, 0 if unknown
+func kernelMajor() int {
+	var u unix.Utsname
+	if err := unix.Uname(&u); err != nil {
+		return 0
+	}
+	release := unix.ByteSliceToString(u.Release[:])
+	major, _, _ := strings.Cut(release, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func readProc(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,43 @@
+//go:build !linux
+
+package transport
+
+import (
+	"log"
+	"sync"
+	"syscall"
+)
+
+var ccWarnOnce sync.Once
+
+// Control returns nil: TCP_CONGESTION is only supported on Linux
+func (c *Config) Control() func(network, address string, rc syscall.RawConn) error {
+	if c.TCPCongestion != "" {
+		ccWarnOnce.Do(func() {
+			log.Printf("[transport] TCP_CONGESTION not supported on this platform, ignoring --tcp-cc=%s", c.TCPCongestion)
+		})
+	}
+	return nil
+}
+
+// EffectiveTCPCongestion is not observable outside Linux
+func EffectiveTCPCongestion(cc string) string {
+	return "unknown"
+}
+
+// quicGSOStatus: quic-go only implements GSO on Linux
+func quicGSOStatus(disabled bool) string {
+	if disabled {
+		return "disabled"
+	}
+	return "unsupported"
+}
+
+// quicECNStatus reports whether quic-go was asked to use ECN; the socket
+// support is only probed on Linux
+func quicECNStatus(disabled bool) string {
+	if disabled {
+		return "disabled"
+	}
+	return "requested"
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// WireCounters counts bytes as they cross the socket: TLS records over TCP,
+// or full QUIC packets (headers, frames and AEAD tags) over UDP
+type WireCounters struct {
+	BytesIn    atomic.Uint64
+	BytesOut   atomic.Uint64
+	PacketsIn  atomic.Uint64 // QUIC only
+	PacketsOut atomic.Uint64 // QUIC only
+	Conns      atomic.Uint64 // Connections accepted
+}
+
+// Listener counts the bytes of every connection accepted from ln
+func (w *WireCounters) Listener(ln net.Listener) net.Listener {
+	return &countingListener{Listener: ln, w: w}
+}
+
+type countingListener struct {
+	net.Listener
+	w *WireCounters
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.w.Conns.Add(1)
+	return &countingConn{Conn: c, w: l.w}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	w *WireCounters
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.w.BytesIn.Add(uint64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.w.BytesOut.Add(uint64(n))
+	return n, err
+}
+
+// QUICTracer counts QUIC packets through a connection tracer, which keeps
+// quic-go's optimized UDP paths (GSO, ECN) that a wrapped PacketConn would lose
+func (w *WireCounters) QUICTracer() func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+	return func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+		w.Conns.Add(1)
+		sent := func(size logging.ByteCount) {
+			w.BytesOut.Add(uint64(size))
+			w.PacketsOut.Add(1)
+		}
+		recv := func(size logging.ByteCount) {
+			w.BytesIn.Add(uint64(size))
+			w.PacketsIn.Add(1)
+		}
+		return &logging.ConnectionTracer{
+			SentLongHeaderPacket: func(_ *logging.ExtendedHeader, size logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, _ []logging.Frame) {
+				sent(size)
+			},
+			SentShortHeaderPacket: func(_ *logging.ShortHeader, size logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, _ []logging.Frame) {
+				sent(size)
+			},
+			ReceivedLongHeaderPacket: func(_ *logging.ExtendedHeader, size logging.ByteCount, _ logging.ECN, _ []logging.Frame) {
+				recv(size)
+			},
+			ReceivedShortHeaderPacket: func(_ *logging.ShortHeader, size logging.ByteCount, _ logging.ECN, _ []logging.Frame) {
+				recv(size)
+			},
+		}
+	}
+}
+
+// ChainTracers combines quic.Config tracers; nil entries and nil
+// per-connection tracers are skipped
+func ChainTracers(tracers ...func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer) func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer {
+	var active []func(context.Context, logging.Perspective, quic.ConnectionID) *logging.ConnectionTracer
+	for _, t := range tracers {
+		if t != nil {
+			active = append(active, t)
+		}
+	}
+	if len(active) == 0 {
+		return nil
+	}
+	return func(ctx context.Context, p logging.Perspective, id quic.ConnectionID) *logging.ConnectionTracer {
+		var cts []*logging.ConnectionTracer
+		for _, t := range active {
+			if ct := t(ctx, p, id); ct != nil {
+				cts = append(cts, ct)
+			}
+		}
+		switch len(cts) {
+		case 0:
+			return nil
+		case 1:
+			return cts[0]
+		}
+		return logging.NewMultiplexedConnectionTracer(cts...)
+	}
+}
@@ -1,6 +1,6 @@
 #!/bin/bash
 # run_all_benchmarks.sh
-# Script untuk menjalankan semua 10 benchmark scenarios untuk HTTP/2 dan HTTP/3
+# Script untuk menjalankan semua 12 benchmark scenarios untuk HTTP/2 dan HTTP/3
 
 set -e
 
@@ -48,6 +48,8 @@ SCENARIOS=(
   "bench-uplink:uplink"
   "bench-migration:migration"
   "bench-stress:stress"
+  "bench-hol:hol"
+  "bench-bulk:bulk"
 )
 
 TOTAL=${#SCENARIOS[@]}